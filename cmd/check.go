@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/check"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/expect"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+)
+
+// checkFiles checks the OTLP JSON files once. Findings in the baseline are
+// not reported. It returns the exit code, 1 if anything was rejected or an
+// expectation wasn't met.
+func checkFiles(path, baselinePath string, files []string) int {
+	cfg, err := readConfig(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if baselinePath != "" {
+		cfg.Baseline = baselinePath
+	}
+	seen := expect.NewSeen()
+	c, ok := newChecker(cfg, seen)
+	if !ok {
+		return 1
+	}
+	res, ok := runChecker(c, files)
+	if !ok {
+		return 1
+	}
+	unmet := seen.Unmet(cfg.Expectations)
+	for _, u := range unmet {
+		fmt.Printf("expectation not met: %s %s %s\n", u.Service, u.Signal, u.Name)
+	}
+
+	for _, e := range c.FixedBaseline() {
+		fmt.Printf("fixed: %s %s %s %q %s %s\n", e.Signal, e.Service, e.Scope, e.Name, e.Group, e.Attribute)
+	}
+	fmt.Printf("%d requests checked, %d items rejected\n", res.Requests, res.Rejected)
+	if res.Rejected > 0 || len(unmet) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// recordBaseline checks the OTLP JSON files and writes every finding to the
+// baseline file.
+func recordBaseline(path, baselinePath string, files []string) int {
+	if baselinePath == "" {
+		fmt.Fprintln(os.Stderr, "-baseline is required")
+		return 1
+	}
+	cfg, err := readConfig(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	// Record without the baseline, so known findings are kept.
+	cfg.Baseline = ""
+	rec := servers.NewBaselineRecorder()
+	c, ok := newChecker(cfg, rec)
+	if !ok {
+		return 1
+	}
+	if _, ok := runChecker(c, files); !ok {
+		return 1
+	}
+
+	f, err := os.Create(baselinePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	b := rec.Baseline()
+	if err := b.Write(f); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		_ = f.Close()
+		return 1
+	}
+	if err := f.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("recorded %d findings in %s\n", len(b.Findings), baselinePath)
+	return 0
+}
+
+func newChecker(cfg servers.Config, obs ...servers.Observer) (*check.Checker, bool) {
+	svs, err := semconv.ParseSemanticVersion()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to parse groups:", err)
+		return nil, false
+	}
+	if err := cfg.Validate(svs); err != nil {
+		for _, err := range flattenErrors(err) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return nil, false
+	}
+	c, err := check.New(cfg, svs, obs...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, false
+	}
+	return c, true
+}
+
+func runChecker(c *check.Checker, files []string) (check.Result, bool) {
+	total := check.Result{}
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "no files to check")
+		return total, false
+	}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return total, false
+		}
+		res, err := c.Check(context.Background(), f)
+		_ = f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", file, err)
+			return total, false
+		}
+		total.Requests += res.Requests
+		total.Rejected += res.Rejected
+	}
+	return total, true
+}
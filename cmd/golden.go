@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/check"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/golden"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+)
+
+// recordGolden records the attributes of the OTLP JSON files to the golden
+// file. The enum values are of the semantic version.
+func recordGolden(path, version string, files []string) int {
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "-golden is required")
+		return 1
+	}
+	rec, ok := runRecorder(version, files)
+	if !ok {
+		return 1
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	g := rec.Golden()
+	if err := g.Write(f); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		_ = f.Close()
+		return 1
+	}
+	if err := f.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("recorded %d items in %s\n", len(g.Items), path)
+	return 0
+}
+
+// diffGolden prints how the attributes of the OTLP JSON files differ from the
+// golden file. It returns 1 if anything changed.
+func diffGolden(path, version string, files []string) int {
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "-golden is required")
+		return 1
+	}
+	g, err := golden.Read(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	rec, ok := runRecorder(version, files)
+	if !ok {
+		return 1
+	}
+
+	changes := rec.Diff(g)
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	fmt.Printf("%d changes from %s\n", len(changes), path)
+	if len(changes) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runRecorder records the files with a checker without matches, so only the
+// attributes are recorded.
+func runRecorder(version string, files []string) (*golden.Recorder, bool) {
+	svs, err := semconv.ParseSemanticVersion()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to parse groups:", err)
+		return nil, false
+	}
+	sv, ok := findVersion(svs, version)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown semantic version %q\n", version)
+		return nil, false
+	}
+	rec := golden.NewRecorder(sv.Registry)
+	c, err := check.New(servers.Config{}, svs, rec)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, false
+	}
+	if _, ok := runChecker(c, files); !ok {
+		return nil, false
+	}
+	return rec, true
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+)
+
+// introspect runs the groups, search and explain commands. It returns the
+// exit code.
+func introspect(cmd, version, groupType string, args []string) int {
+	svs, err := semconv.ParseSemanticVersion()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to parse groups:", err)
+		return 1
+	}
+	sv, ok := findVersion(svs, version)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown semantic version %q\n", version)
+		return 1
+	}
+
+	switch cmd {
+	case "groups":
+		if groupType != "" && !slices.Contains(semconv.GroupTypes, groupType) {
+			fmt.Fprintf(os.Stderr, "unknown group type %q, one of %s\n", groupType, strings.Join(semconv.GroupTypes, ", "))
+			return 1
+		}
+		listGroups(os.Stdout, sv.GroupsOfType(groupType))
+	case "search":
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "usage: search [-semantic_version url] keyword")
+			return 1
+		}
+		search(os.Stdout, sv.Search(args[0]))
+	case "explain":
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "usage: explain [-semantic_version url] group")
+			return 1
+		}
+		g, ok := sv.Groups[args[0]]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown group %q, search finds group ids\n", args[0])
+			return 1
+		}
+		explain(os.Stdout, g)
+	}
+	return 0
+}
+
+// findVersion finds the semantic version by its schema URL, or by the
+// version at its end, e.g. 1.24.0.
+func findVersion(svs map[string]semconv.SemanticVersion, version string) (semconv.SemanticVersion, bool) {
+	if sv, ok := svs[version]; ok {
+		return sv, true
+	}
+	for url, sv := range svs {
+		if strings.HasSuffix(url, "/"+strings.TrimPrefix(version, "v")) {
+			return sv, true
+		}
+	}
+	return semconv.SemanticVersion{}, false
+}
+
+func listGroups(w io.Writer, groups []semconv.Group) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP\tTYPE\tSTABILITY\tBRIEF")
+	for _, g := range groups {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", g.Id, g.Type, g.Stability, firstLine(g.Brief))
+	}
+	tw.Flush()
+}
+
+func search(w io.Writer, results []semconv.SearchResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP\tATTRIBUTE\tBRIEF")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Group, r.Attribute, firstLine(r.Brief))
+	}
+	tw.Flush()
+}
+
+// explain prints the group with its attributes after extends and refs are
+// resolved. The source is the group that lists the attribute.
+func explain(w io.Writer, g semconv.Group) {
+	fmt.Fprintf(w, "%s (%s)\n", g.Id, g.Type)
+	if g.Brief != "" {
+		fmt.Fprintln(w, strings.TrimSpace(g.Brief))
+	}
+	if g.Extends != "" {
+		fmt.Fprintf(w, "extends %s\n", g.Extends)
+	}
+	if g.MetricName != "" {
+		fmt.Fprintf(w, "metric %s %s %s\n", g.MetricName, g.Instrument, g.Unit)
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ATTRIBUTE\tLEVEL\tTYPE\tSTABILITY\tSOURCE")
+	for _, a := range g.Attributes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", a.CanonicalId, a.Level(), a.Type.Name, g.AttributeStability(a), a.Source)
+	}
+	tw.Flush()
+}
+
+// firstLine shortens a brief to its first line for the tables.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
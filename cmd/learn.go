@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/check"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/learn"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+)
+
+// learnedAddress is the server_address of a config learned from files, as in
+// the default config.
+const learnedAddress = "0.0.0.0:4317"
+
+// learnFiles runs the OTLP JSON files through a checker without matches, and
+// writes the config suggested from them to out, or stdout if it is empty. It
+// returns the exit code.
+func learnFiles(version, out string, files []string) int {
+	svs, err := semconv.ParseSemanticVersion()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to parse groups:", err)
+		return 1
+	}
+	l, err := newLearner(svs, version)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	c, err := check.New(servers.Config{}, svs, l)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if _, ok := runChecker(c, files); !ok {
+		return 1
+	}
+	if err := writeLearned(l, out, learnedAddress); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func newLearner(svs map[string]semconv.SemanticVersion, version string) (*learn.Learner, error) {
+	sv, ok := findVersion(svs, version)
+	if !ok {
+		return nil, fmt.Errorf("unknown semantic version %q", version)
+	}
+	return learn.New(sv), nil
+}
+
+// writeLearned writes the suggested config, with the server address, to the
+// file, or stdout if path is empty.
+func writeLearned(l *learn.Learner, path, address string) error {
+	cfg := l.Config()
+	cfg.ServerAddress = address
+	if path == "" {
+		return cfg.Write(os.Stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -3,14 +3,32 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/expect"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/learn"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/score"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/selftelemetry"
 	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
 	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/session"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/stats"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/viper"
 	pbLog "go.opentelemetry.io/proto/otlp/collector/logs/v1"
 	pbMetric "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
@@ -19,11 +37,63 @@ import (
 	_ "google.golang.org/grpc/encoding/gzip" // Install the gzip compressor
 )
 
-var config = flag.String("cfg", "config.yaml", "The config file to use.")
+var (
+	config = flag.String("cfg", "config.yaml", "The config file to use.")
+	watch  = flag.Bool("watch", false, "Reload the config file when it changes.")
+	report = flag.String("report", "", "Print the compliance report on shutdown, one of table, markdown or json.")
+	// baselineFile is the baseline to filter with, or to record to with the
+	// baseline command.
+	baselineFile = flag.String("baseline", "", "The baseline file of known findings, overrides the config.")
+	// semanticVersion and groupType are for the groups, search and explain
+	// commands.
+	semanticVersion = flag.String("semantic_version", semconv.DefaultVersion, "The semantic version to list, search or explain, a schema URL or a version like 1.24.0.")
+	groupType       = flag.String("type", "", "The type of groups to list, one of span, metric, resource, event or attribute_group.")
+	// learnFile is where the config suggested from the telemetry is written,
+	// with -semantic_version for its groups.
+	learnFile = flag.String("learn", "", "Write a config suggested from the received telemetry to this file on shutdown.")
+	learnFor  = flag.Duration("learn_for", 0, "Stop the server after this long, e.g. to learn a config.")
+	// goldenFile is recorded by the golden command and compared against by
+	// the diff command.
+	goldenFile = flag.String("golden", "", "The golden file of recorded attributes.")
+)
 
 func main() {
 	flag.Parse()
 
+	if flag.Arg(0) == "validate" {
+		// Allow flags after the subcommand, e.g. validate -cfg config.yaml
+		_ = flag.CommandLine.Parse(flag.Args()[1:])
+		os.Exit(validate(*config))
+	}
+	if cmd := flag.Arg(0); cmd == "check" || cmd == "baseline" {
+		_ = flag.CommandLine.Parse(flag.Args()[1:])
+		if cmd == "check" {
+			os.Exit(checkFiles(*config, *baselineFile, flag.Args()))
+		}
+		os.Exit(recordBaseline(*config, *baselineFile, flag.Args()))
+	}
+
+	if cmd := flag.Arg(0); cmd == "golden" || cmd == "diff" {
+		_ = flag.CommandLine.Parse(flag.Args()[1:])
+		if cmd == "golden" {
+			os.Exit(recordGolden(*goldenFile, *semanticVersion, flag.Args()))
+		}
+		os.Exit(diffGolden(*goldenFile, *semanticVersion, flag.Args()))
+	}
+	if flag.Arg(0) == "learn" {
+		_ = flag.CommandLine.Parse(flag.Args()[1:])
+		os.Exit(learnFiles(*semanticVersion, *learnFile, flag.Args()))
+	}
+	if cmd := flag.Arg(0); cmd == "groups" || cmd == "search" || cmd == "explain" {
+		_ = flag.CommandLine.Parse(flag.Args()[1:])
+		os.Exit(introspect(cmd, *semanticVersion, *groupType, flag.Args()))
+	}
+
+	if *report != "" && !slices.Contains(score.Formats, *report) {
+		slog.Error("unknown report format", "report", *report, "formats", score.Formats)
+		return
+	}
+
 	svs, err := semconv.ParseSemanticVersion()
 	if err != nil {
 		slog.Error("failed to parse groups", "error", err)
@@ -33,15 +103,81 @@ func main() {
 	cfg := servers.Config{}
 
 	viper.SetConfigFile(*config)
+	fileRead := true
 	if err := viper.ReadInConfig(); err != nil {
 		fmt.Println(err)
 		viper.SetConfigType("yaml")
 		_ = viper.ReadConfig(strings.NewReader(servers.DefaultConfig))
+		fileRead = false
 	}
 	if err := viper.Unmarshal(&cfg); err != nil {
 		slog.Error("failed to unmarshal config", "error", err)
 		return
 	}
+	if *baselineFile != "" {
+		cfg.Baseline = *baselineFile
+	}
+	if err := cfg.Validate(svs); err != nil {
+		for _, err := range flattenErrors(err) {
+			slog.Error("invalid config", "error", err)
+		}
+		return
+	}
+
+	reg := prometheus.NewRegistry()
+	st, err := stats.New(reg)
+	if err != nil {
+		slog.Error("failed to create stats", "error", err)
+		return
+	}
+	sessions := session.NewStore()
+	sessions.SetExpectations(cfg.Expectations)
+	seen := expect.NewSeen()
+	observers := []servers.Observer{st, sessions, seen}
+
+	var learner *learn.Learner
+	if *learnFile != "" {
+		learner, err = newLearner(svs, *semanticVersion)
+		if err != nil {
+			slog.Error("failed to create learner", "error", err)
+			return
+		}
+		observers = append(observers, learner)
+	}
+
+	var exporter *selftelemetry.Exporter
+	if cfg.SelfTelemetry.Endpoint != "" {
+		exporter, err = selftelemetry.New(cfg.SelfTelemetry)
+		if err != nil {
+			slog.Error("failed to create self telemetry exporter", "error", err)
+			return
+		}
+		observers = append(observers, exporter)
+	}
+
+	traceServer, err := servers.NewTraceService(cfg, svs, observers...)
+	if err != nil {
+		slog.Error("failed to create trace server", "error", err)
+		return
+	}
+	metricsServer, err := servers.NewMetricsService(cfg, svs, observers...)
+	if err != nil {
+		slog.Error("failed to create metrics server", "error", err)
+		return
+	}
+	logServer, err := servers.NewLogService(cfg, svs, observers...)
+	if err != nil {
+		slog.Error("failed to create log server", "error", err)
+		return
+	}
+
+	if *watch && fileRead {
+		viper.OnConfigChange(func(e fsnotify.Event) {
+			reload(svs, sessions, traceServer, metricsServer, logServer)
+		})
+		viper.WatchConfig()
+		slog.Info("watching config", "file", *config)
+	}
 
 	lis, err := net.Listen("tcp", cfg.ServerAddress)
 	if err != nil {
@@ -50,13 +186,142 @@ func main() {
 	}
 
 	grpcServer := grpc.NewServer()
-	pbTrace.RegisterTraceServiceServer(grpcServer, servers.NewTraceService(cfg, svs))
-	pbMetric.RegisterMetricsServiceServer(grpcServer, servers.NewMetricsService(cfg, svs))
-	pbLog.RegisterLogsServiceServer(grpcServer, servers.NewLogService(cfg, svs))
+	pbTrace.RegisterTraceServiceServer(grpcServer, traceServer)
+	pbMetric.RegisterMetricsServiceServer(grpcServer, metricsServer)
+	pbLog.RegisterLogsServiceServer(grpcServer, logServer)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+	}()
+	if *learnFor > 0 {
+		timer := time.AfterFunc(*learnFor, stop)
+		defer timer.Stop()
+	}
+
+	if cfg.HTTPAddress != "" {
+		go serveHTTP(ctx, cfg.HTTPAddress, reg, sessions)
+	}
+	if cfg.ExpectationTimeout > 0 {
+		timer := time.AfterFunc(cfg.ExpectationTimeout, func() {
+			logUnmet(seen.Unmet(sessions.Expectations()))
+		})
+		defer timer.Stop()
+	}
+	exported := make(chan struct{})
+	if exporter != nil {
+		go func() {
+			exporter.Run(ctx)
+			close(exported)
+		}()
+	} else {
+		close(exported)
+	}
 
 	slog.Info("starting server", "address", cfg.ServerAddress)
 	if err := grpcServer.Serve(lis); err != nil {
 		slog.Error("failed to serve", "error", err)
 		return
 	}
+
+	unused := servers.UnusedIgnores(traceServer.IgnoreUsage(), metricsServer.IgnoreUsage(), logServer.IgnoreUsage())
+	for section, patterns := range unused {
+		slog.Info("unused ignore entries", "section", section, "ignore", patterns)
+	}
+	for _, e := range servers.FixedBaseline(traceServer.FixedBaseline(), metricsServer.FixedBaseline(), logServer.FixedBaseline()) {
+		slog.Info("fixed baseline entry", "signal", e.Signal, "service", e.Service, "scope", e.Scope, "name", e.Name, "group", e.Group, "attribute", e.Attribute)
+	}
+	<-exported
+
+	if learner != nil {
+		if err := writeLearned(learner, *learnFile, cfg.ServerAddress); err != nil {
+			slog.Error("failed to write suggested config", "error", err)
+		} else {
+			slog.Info("wrote suggested config", "file", *learnFile)
+		}
+	}
+	if *report != "" {
+		if err := sessions.ReportAll().Write(os.Stdout, *report); err != nil {
+			slog.Error("failed to write report", "error", err)
+		}
+	}
+}
+
+func logUnmet(unmet []expect.Unmet) {
+	for _, u := range unmet {
+		slog.Warn("expectation not met", "service", u.Service, "signal", u.Signal, "name", u.Name)
+	}
+}
+
+// serveHTTP serves the registry on /metrics, the compliance report of a
+// session on /report and the session API until ctx is done.
+func serveHTTP(ctx context.Context, addr string, reg *prometheus.Registry, sessions *session.Store) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	mux.Handle(session.Prefix, session.Handler(sessions))
+	mux.HandleFunc("/report", func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("session")
+		report, ok := sessions.Report(name)
+		if !ok && name != "" {
+			http.Error(w, fmt.Sprintf("unknown session %q", name), http.StatusNotFound)
+			return
+		}
+		buf := &bytes.Buffer{}
+		if err := report.Write(buf, r.URL.Query().Get("format")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_, _ = buf.WriteTo(w)
+	})
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+	}()
+
+	slog.Info("starting http server", "address", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("failed to serve http", "error", err)
+	}
+}
+
+// reload applies the changed config to the servers. An invalid config is
+// logged and the servers keep the previous one.
+func reload(svs map[string]semconv.SemanticVersion, sessions *session.Store, traceServer *servers.TraceServer, metricsServer *servers.MetricsServer, logServer *servers.LogServer) {
+	cfg := servers.Config{}
+	if err := viper.Unmarshal(&cfg); err != nil {
+		slog.Error("failed to reload config", "error", err)
+		return
+	}
+	if *baselineFile != "" {
+		cfg.Baseline = *baselineFile
+	}
+	if err := cfg.Validate(svs); err != nil {
+		for _, err := range flattenErrors(err) {
+			slog.Error("invalid config, keeping previous config", "error", err)
+		}
+		return
+	}
+
+	// The config is valid, so the updates can't fail.
+	_ = traceServer.Update(cfg, svs)
+	_ = metricsServer.Update(cfg, svs)
+	_ = logServer.Update(cfg, svs)
+	sessions.SetExpectations(cfg.Expectations)
+	slog.Info("reloaded config")
+}
+
+// flattenErrors splits joined errors so each can be reported on its own.
+func flattenErrors(err error) []error {
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		return []error{err}
+	}
+	errs := []error{}
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, flattenErrors(e)...)
+	}
+	return errs
 }
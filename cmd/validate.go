@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	"github.com/spf13/viper"
+)
+
+// validate checks the config file and prints every problem found. It returns
+// the exit code.
+func validate(path string) int {
+	svs, err := semconv.ParseSemanticVersion()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to parse groups:", err)
+		return 1
+	}
+
+	cfg, err := readConfig(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if err := cfg.Validate(svs); err != nil {
+		for _, err := range flattenErrors(err) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return 1
+	}
+	fmt.Printf("%s is valid\n", path)
+	return 0
+}
+
+func readConfig(path string) (servers.Config, error) {
+	cfg := servers.Config{}
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		return cfg, err
+	}
+	err := viper.Unmarshal(&cfg)
+	return cfg, err
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package check runs telemetry saved as OTLP JSON through the servers, to
+// check it without running the checker as a collector.
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	pbCollectorLogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	pbCollectorMetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	pbCollectorTrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Checker checks OTLP JSON export requests, like those written by the
+// collector file exporter.
+type Checker struct {
+	trace   *servers.TraceServer
+	metrics *servers.MetricsServer
+	logs    *servers.LogServer
+}
+
+func New(cfg servers.Config, svs map[string]semconv.SemanticVersion, obs ...servers.Observer) (*Checker, error) {
+	trace, err := servers.NewTraceService(cfg, svs, obs...)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := servers.NewMetricsService(cfg, svs, obs...)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := servers.NewLogService(cfg, svs, obs...)
+	if err != nil {
+		return nil, err
+	}
+	return &Checker{trace: trace, metrics: metrics, logs: logs}, nil
+}
+
+// Result is the outcome of checking requests.
+type Result struct {
+	Requests int
+	// Rejected is the number of spans, data points and log records that
+	// failed their error policy.
+	Rejected int64
+}
+
+func (r *Result) add(o Result) {
+	r.Requests += o.Requests
+	r.Rejected += o.Rejected
+}
+
+// Check checks every request in r. The requests can be one JSON document or a
+// stream of them, e.g. one per line.
+func (c *Checker) Check(ctx context.Context, r io.Reader) (Result, error) {
+	res := Result{}
+	dec := json.NewDecoder(r)
+	for {
+		raw := json.RawMessage{}
+		err := dec.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return res, nil
+		}
+		if err != nil {
+			return res, fmt.Errorf("request %d: %w", res.Requests+1, err)
+		}
+		rejected, err := c.checkRequest(ctx, raw)
+		if err != nil {
+			return res, fmt.Errorf("request %d: %w", res.Requests+1, err)
+		}
+		res.add(Result{Requests: 1, Rejected: rejected})
+	}
+}
+
+func (c *Checker) checkRequest(ctx context.Context, raw json.RawMessage) (int64, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return 0, err
+	}
+	has := func(names ...string) bool {
+		for _, n := range names {
+			if _, ok := fields[n]; ok {
+				return true
+			}
+		}
+		return false
+	}
+	opts := protojson.UnmarshalOptions{DiscardUnknown: true}
+
+	switch {
+	case has("resourceSpans", "resource_spans"):
+		req := &pbCollectorTrace.ExportTraceServiceRequest{}
+		if err := opts.Unmarshal(raw, req); err != nil {
+			return 0, err
+		}
+		resp, _ := c.trace.Export(ctx, req)
+		return resp.GetPartialSuccess().GetRejectedSpans(), nil
+	case has("resourceMetrics", "resource_metrics"):
+		req := &pbCollectorMetrics.ExportMetricsServiceRequest{}
+		if err := opts.Unmarshal(raw, req); err != nil {
+			return 0, err
+		}
+		resp, _ := c.metrics.Export(ctx, req)
+		return resp.GetPartialSuccess().GetRejectedDataPoints(), nil
+	case has("resourceLogs", "resource_logs"):
+		req := &pbCollectorLogs.ExportLogsServiceRequest{}
+		if err := opts.Unmarshal(raw, req); err != nil {
+			return 0, err
+		}
+		resp, _ := c.logs.Export(ctx, req)
+		return resp.GetPartialSuccess().GetRejectedLogRecords(), nil
+	}
+	return 0, errors.New("not an OTLP trace, metrics or logs request")
+}
+
+// FixedBaseline returns the baseline entries that were checked and no longer
+// found.
+func (c *Checker) FixedBaseline() []servers.BaselineEntry {
+	return servers.FixedBaseline(c.trace.FixedBaseline(), c.metrics.FixedBaseline(), c.logs.FixedBaseline())
+}
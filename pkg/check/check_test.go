@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package check
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const requests = `{"resourceSpans":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},"scopeSpans":[{"scope":{"name":"otelhttp"},"spans":[{"name":"GET /users","attributes":[{"key":"app.id","value":{"stringValue":"1"}}]},{"name":"GET /orders"}]}]}]}
+{"resource_logs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},"scope_logs":[{"log_records":[{"body":{"string_value":"hello"}}]}]}]}
+`
+
+func testConfig() servers.Config {
+	return servers.Config{
+		ServerAddress: "localhost:4317",
+		Trace:         []servers.Match{{Include: []string{"app.id"}}},
+		Log:           []servers.Match{{Include: []string{"app.id"}}},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	rec := servers.NewBaselineRecorder()
+	c, err := New(testConfig(), svs, rec)
+	require.NoError(t, err)
+
+	res, err := c.Check(context.Background(), strings.NewReader(requests))
+	require.NoError(t, err)
+	assert.Equal(t, Result{Requests: 2, Rejected: 2}, res)
+
+	assert.Equal(t, []servers.BaselineEntry{
+		{Signal: "log", Service: "api", Name: "hello", Group: "include", Attribute: "app.id", Category: servers.RequiredMissing},
+		{Signal: "trace", Service: "api", Scope: "otelhttp", Name: "GET /orders", Group: "include", Attribute: "app.id", Category: servers.RequiredMissing},
+	}, rec.Baseline().Findings)
+}
+
+func TestCheckBaseline(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "baseline.yaml")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, servers.Baseline{Findings: []servers.BaselineEntry{
+		{Signal: "trace", Service: "api", Scope: "otelhttp", Name: "GET /orders", Group: "include", Attribute: "app.id"},
+		{Signal: "trace", Service: "api", Scope: "otelhttp", Name: "GET /users", Group: "include", Attribute: "app.id"},
+		{Signal: "trace", Service: "web", Name: "GET /", Group: "include", Attribute: "app.id"},
+	}}.Write(f))
+	require.NoError(t, f.Close())
+
+	cfg := testConfig()
+	cfg.Baseline = path
+	rec := servers.NewBaselineRecorder()
+	c, err := New(cfg, svs, rec)
+	require.NoError(t, err)
+
+	res, err := c.Check(context.Background(), strings.NewReader(requests))
+	require.NoError(t, err)
+	assert.Equal(t, Result{Requests: 2, Rejected: 1}, res, "only the log record isn't in the baseline")
+	assert.Len(t, rec.Baseline().Findings, 2, "observers see the baselined findings")
+
+	// web was never checked, so only GET /users is known to be fixed.
+	assert.Equal(t, []servers.BaselineEntry{
+		{Signal: "trace", Service: "api", Scope: "otelhttp", Name: "GET /users", Group: "include", Attribute: "app.id"},
+	}, c.FixedBaseline())
+}
+
+func TestCheckInvalid(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+	c, err := New(testConfig(), svs)
+	require.NoError(t, err)
+
+	_, err = c.Check(context.Background(), strings.NewReader(`{"spans":[]}`))
+	assert.EqualError(t, err, "request 1: not an OTLP trace, metrics or logs request")
+
+	_, err = c.Check(context.Background(), strings.NewReader(`{"resourceSpans":`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package expect tracks the telemetry services have sent, to report
+// expectations that were never met.
+package expect
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+)
+
+// Seen records the groups and metric names each service sent. It is a
+// servers.Observer.
+type Seen struct {
+	mu   sync.Mutex
+	keys map[key]bool
+}
+
+type key struct {
+	service, signal, name string
+}
+
+var _ servers.Observer = &Seen{}
+
+func NewSeen() *Seen {
+	return &Seen{keys: map[key]bool{}}
+}
+
+func (s *Seen) Observe(item servers.Item) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if item.Signal == "metrics" {
+		s.keys[key{item.Service, item.Signal, item.Name}] = true
+	}
+	for _, g := range item.Groups {
+		s.keys[key{item.Service, item.Signal, g}] = true
+	}
+}
+
+// Unmet is telemetry an expectation named that was never sent.
+type Unmet struct {
+	Service string `json:"service"`
+	Signal  string `json:"signal"`
+	// Name is the group, or the metric name.
+	Name string `json:"name"`
+}
+
+// Unmet returns the telemetry of the expectations that wasn't seen.
+func (s *Seen) Unmet(expectations []servers.Expectation) []Unmet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unmet := []Unmet{}
+	for _, e := range expectations {
+		for _, l := range []struct {
+			signal string
+			names  []string
+		}{{"trace", e.Trace}, {"metrics", e.Metrics}, {"log", e.Log}} {
+			for _, name := range l.names {
+				if !s.keys[key{e.Service, l.signal, name}] {
+					unmet = append(unmet, Unmet{Service: e.Service, Signal: l.signal, Name: name})
+				}
+			}
+		}
+	}
+	sort.Slice(unmet, func(i, j int) bool {
+		a, b := unmet[i], unmet[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Signal != b.Signal {
+			return a.Signal < b.Signal
+		}
+		return a.Name < b.Name
+	})
+	return unmet
+}
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package expect
+
+import (
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeenUnmet(t *testing.T) {
+	expectations := []servers.Expectation{
+		{
+			Service: "api",
+			Trace:   []string{"trace.http.server", "trace.db"},
+			Metrics: []string{"http.server.duration"},
+		},
+		{Service: "web", Log: []string{"event.exception"}},
+	}
+
+	s := NewSeen()
+	s.Observe(servers.Item{Signal: "trace", Service: "api", Groups: []string{"trace.http.server"}})
+	s.Observe(servers.Item{Signal: "metrics", Service: "api", Name: "http.server.duration"})
+	// The same group from another service or signal doesn't count.
+	s.Observe(servers.Item{Signal: "trace", Service: "web", Groups: []string{"trace.db"}})
+	s.Observe(servers.Item{Signal: "trace", Service: "web", Groups: []string{"event.exception"}})
+
+	assert.Equal(t, []Unmet{
+		{Service: "api", Signal: "trace", Name: "trace.db"},
+		{Service: "web", Signal: "log", Name: "event.exception"},
+	}, s.Unmet(expectations))
+
+	assert.Empty(t, s.Unmet(nil))
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package golden
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ChangeKind is how an item or attribute differs from the golden file.
+type ChangeKind string
+
+const (
+	// ItemAdded is an item name that isn't in the golden file.
+	ItemAdded ChangeKind = "item-added"
+	// ItemRemoved is an item name of the golden file that wasn't seen.
+	ItemRemoved  ChangeKind = "item-removed"
+	Added        ChangeKind = "added"
+	Removed      ChangeKind = "removed"
+	TypeChanged  ChangeKind = "type-changed"
+	ValueAdded   ChangeKind = "value-added"
+	ValueRemoved ChangeKind = "value-removed"
+)
+
+// Change is a difference from the golden file. Attribute is empty for the
+// item changes.
+type Change struct {
+	Signal    string
+	Service   string
+	Scope     string
+	Name      string
+	Attribute string
+	Kind      ChangeKind
+	// Golden and Got are the type, or enum value, that changed.
+	Golden string
+	Got    string
+}
+
+func (c Change) String() string {
+	s := fmt.Sprintf("%s %s %s %q", c.Signal, c.Service, c.Scope, c.Name)
+	if c.Attribute != "" {
+		s += " " + c.Attribute
+	}
+	s += ": " + string(c.Kind)
+	switch c.Kind {
+	case TypeChanged:
+		s += fmt.Sprintf(" %s -> %s", c.Golden, c.Got)
+	case ValueAdded:
+		s += " " + c.Got
+	case ValueRemoved:
+		s += " " + c.Golden
+	}
+	return s
+}
+
+// Diff compares what was recorded against the golden file. The types are
+// compared as recorded, so a type that appears, disappears or changes on any
+// value is a change. The changes are sorted by item, then attribute.
+func (r *Recorder) Diff(g Golden) []Change {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changes := []Change{}
+	golden := map[itemKey]bool{}
+	for _, item := range g.Items {
+		key := item.key()
+		golden[key] = true
+		change := func(attribute string, kind ChangeKind, want, got string) {
+			changes = append(changes, Change{
+				Signal: key.signal, Service: key.service, Scope: key.scope, Name: key.name,
+				Attribute: attribute, Kind: kind, Golden: want, Got: got,
+			})
+		}
+		rec, ok := r.items[key]
+		if !ok {
+			change("", ItemRemoved, "", "")
+			continue
+		}
+
+		names := map[string]bool{}
+		for _, a := range item.Attributes {
+			names[a.Name] = true
+		}
+		for name := range rec.types {
+			if !names[name] {
+				change(name, Added, "", "")
+			}
+		}
+
+		for _, a := range item.Attributes {
+			if _, ok := rec.types[a.Name]; !ok {
+				change(a.Name, Removed, "", "")
+				continue
+			}
+			if got := rec.typeOf(a.Name); got != a.Type {
+				change(a.Name, TypeChanged, a.Type, got)
+			}
+			want := map[string]bool{}
+			for _, v := range a.Values {
+				want[v] = true
+			}
+			for _, v := range sortedKeys(rec.values[a.Name]) {
+				if !want[v] {
+					change(a.Name, ValueAdded, "", v)
+				}
+			}
+			for _, v := range a.Values {
+				if !rec.values[a.Name][v] {
+					change(a.Name, ValueRemoved, v, "")
+				}
+			}
+		}
+	}
+	for key := range r.items {
+		if !golden[key] {
+			changes = append(changes, Change{Signal: key.signal, Service: key.service, Scope: key.scope, Name: key.name, Kind: ItemAdded})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		ka := itemKey{a.Signal, a.Service, a.Scope, a.Name}
+		kb := itemKey{b.Signal, b.Service, b.Scope, b.Name}
+		if ka != kb {
+			return ka.less(kb)
+		}
+		return a.Attribute < b.Attribute
+	})
+	return changes
+}
@@ -0,0 +1,262 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package golden records the attributes of telemetry per span, metric and log
+// name, and diffs later runs against the recording, independent of the
+// semantic convention groups.
+package golden
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// maxValues bounds the values recorded for an enum attribute, the lowest
+// are kept so the recording doesn't depend on the order they arrive in.
+const maxValues = 50
+
+// eventName names log records, their bodies are often unique per record.
+const eventName = "event.name"
+
+// Golden is a recording of the attributes of each item name.
+type Golden struct {
+	Items []Item `yaml:"items"`
+}
+
+// Item is the attributes seen on the spans, data points or log records with
+// a name. A log record is named by its event.name, empty without one.
+type Item struct {
+	Signal     string      `yaml:"signal"`
+	Service    string      `yaml:"service"`
+	Scope      string      `yaml:"scope,omitempty"`
+	Name       string      `yaml:"name"`
+	Attributes []Attribute `yaml:"attributes"`
+}
+
+type Attribute struct {
+	Name string `yaml:"name"`
+	// Type is the type of the value, e.g. string or int[]. The types are
+	// joined by |, e.g. int|string, when the values disagree.
+	Type string `yaml:"type"`
+	// Values are the values of an enum attribute of the semantic conventions,
+	// e.g. http.request.method.
+	Values []string `yaml:"values,omitempty"`
+}
+
+// Read reads a golden file.
+func Read(path string) (Golden, error) {
+	g := Golden{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return g, fmt.Errorf("golden: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &g); err != nil {
+		return g, fmt.Errorf("golden: %s: %w", path, err)
+	}
+	return g, nil
+}
+
+// Write writes the golden file as YAML.
+func (g Golden) Write(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(g); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+type itemKey struct {
+	signal, service, scope, name string
+}
+
+func (i Item) key() itemKey {
+	return itemKey{i.Signal, i.Service, i.Scope, i.Name}
+}
+
+// recorded is what was seen of the attributes of an item name.
+type recorded struct {
+	// types are the types of the values of each key.
+	types map[string]map[string]bool
+	// values are the values of the enum attributes.
+	values map[string]map[string]bool
+}
+
+// typeOf is the type of the attribute, the types joined by | if its values
+// disagree.
+func (rec *recorded) typeOf(name string) string {
+	return strings.Join(sortedKeys(rec.types[name]), "|")
+}
+
+// Recorder records the attributes of the items. It is a servers.Observer.
+type Recorder struct {
+	// registry finds the enum attributes, values aren't recorded if it is
+	// nil.
+	registry *semconv.Registry
+
+	mu    sync.Mutex
+	items map[itemKey]*recorded
+}
+
+var _ servers.Observer = &Recorder{}
+
+func NewRecorder(registry *semconv.Registry) *Recorder {
+	return &Recorder{registry: registry, items: map[itemKey]*recorded{}}
+}
+
+func (r *Recorder) Observe(item servers.Item) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := itemKey{item.Signal, item.Service, item.Scope, itemName(item)}
+	rec, ok := r.items[key]
+	if !ok {
+		rec = &recorded{types: map[string]map[string]bool{}, values: map[string]map[string]bool{}}
+		r.items[key] = rec
+	}
+	for _, a := range item.Attributes {
+		types := rec.types[a.GetKey()]
+		if types == nil {
+			types = map[string]bool{}
+			rec.types[a.GetKey()] = types
+		}
+		types[typeOf(a.GetValue())] = true
+		if !r.isEnum(a.GetKey()) {
+			continue
+		}
+		values := rec.values[a.GetKey()]
+		if values == nil {
+			values = map[string]bool{}
+			rec.values[a.GetKey()] = values
+		}
+		if s, ok := enumValue(a.GetValue()); ok {
+			addValue(values, s)
+		}
+	}
+}
+
+// itemName is the name of the item, the event.name of a log record.
+func itemName(item servers.Item) string {
+	if item.Signal != "log" {
+		return item.Name
+	}
+	for _, a := range item.Attributes {
+		if a.GetKey() == eventName {
+			return a.GetValue().GetStringValue()
+		}
+	}
+	return ""
+}
+
+// addValue adds the value, keeping the lowest maxValues values.
+func addValue(values map[string]bool, v string) {
+	if values[v] {
+		return
+	}
+	if len(values) < maxValues {
+		values[v] = true
+		return
+	}
+	highest := ""
+	for k := range values {
+		if k > highest {
+			highest = k
+		}
+	}
+	if v < highest {
+		delete(values, highest)
+		values[v] = true
+	}
+}
+
+func (r *Recorder) isEnum(key string) bool {
+	if r.registry == nil {
+		return false
+	}
+	a, ok := r.registry.Attribute(key)
+	return ok && len(a.Type.Members) > 0
+}
+
+// enumValue is the value of an enum attribute, they are strings or ints.
+func enumValue(v *v1.AnyValue) (string, bool) {
+	switch v := v.GetValue().(type) {
+	case *v1.AnyValue_StringValue:
+		return v.StringValue, true
+	case *v1.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10), true
+	}
+	return "", false
+}
+
+// typeOf is the type of the value as in the semantic conventions, e.g.
+// string or int[].
+func typeOf(v *v1.AnyValue) string {
+	switch v := v.GetValue().(type) {
+	case *v1.AnyValue_StringValue:
+		return "string"
+	case *v1.AnyValue_IntValue:
+		return "int"
+	case *v1.AnyValue_DoubleValue:
+		return "double"
+	case *v1.AnyValue_BoolValue:
+		return "boolean"
+	case *v1.AnyValue_BytesValue:
+		return "bytes"
+	case *v1.AnyValue_KvlistValue:
+		return "map"
+	case *v1.AnyValue_ArrayValue:
+		if values := v.ArrayValue.GetValues(); len(values) > 0 {
+			return typeOf(values[0]) + "[]"
+		}
+		return "array"
+	}
+	return "empty"
+}
+
+// Golden returns the recording, sorted so the file diffs well.
+func (r *Recorder) Golden() Golden {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	g := Golden{Items: make([]Item, 0, len(r.items))}
+	for key, rec := range r.items {
+		item := Item{Signal: key.signal, Service: key.service, Scope: key.scope, Name: key.name, Attributes: []Attribute{}}
+		for name := range rec.types {
+			item.Attributes = append(item.Attributes, Attribute{Name: name, Type: rec.typeOf(name), Values: sortedKeys(rec.values[name])})
+		}
+		sort.Slice(item.Attributes, func(i, j int) bool { return item.Attributes[i].Name < item.Attributes[j].Name })
+		g.Items = append(g.Items, item)
+	}
+	sort.Slice(g.Items, func(i, j int) bool { return g.Items[i].key().less(g.Items[j].key()) })
+	return g
+}
+
+func (k itemKey) less(o itemKey) bool {
+	for _, p := range [][2]string{{k.signal, o.signal}, {k.service, o.service}, {k.scope, o.scope}, {k.name, o.name}} {
+		if p[0] != p[1] {
+			return p[0] < p[1]
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package golden
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/check"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+func stringAttr(key, value string) *v1.KeyValue {
+	return &v1.KeyValue{Key: key, Value: &v1.AnyValue{Value: &v1.AnyValue_StringValue{StringValue: value}}}
+}
+
+func intAttr(key string, value int64) *v1.KeyValue {
+	return &v1.KeyValue{Key: key, Value: &v1.AnyValue{Value: &v1.AnyValue_IntValue{IntValue: value}}}
+}
+
+func span(name string, attrs ...*v1.KeyValue) servers.Item {
+	return servers.Item{Signal: "trace", Service: "api", Scope: "otelhttp", Name: name, Attributes: attrs}
+}
+
+func newTestRecorder(t *testing.T) *Recorder {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+	return NewRecorder(svs["https://opentelemetry.io/schemas/1.24.0"].Registry)
+}
+
+func TestRecorderGolden(t *testing.T) {
+	r := newTestRecorder(t)
+	r.Observe(span("GET /users", stringAttr("http.request.method", "GET"), intAttr("http.response.status_code", 200)))
+	r.Observe(span("GET /users", stringAttr("http.request.method", "HEAD"), stringAttr("error.type", "timeout")))
+	r.Observe(span("GET /orders", &v1.KeyValue{Key: "app.ids", Value: &v1.AnyValue{Value: &v1.AnyValue_ArrayValue{
+		ArrayValue: &v1.ArrayValue{Values: []*v1.AnyValue{{Value: &v1.AnyValue_IntValue{IntValue: 1}}}},
+	}}}))
+
+	assert.Equal(t, Golden{Items: []Item{
+		{Signal: "trace", Service: "api", Scope: "otelhttp", Name: "GET /orders", Attributes: []Attribute{
+			{Name: "app.ids", Type: "int[]"},
+		}},
+		{Signal: "trace", Service: "api", Scope: "otelhttp", Name: "GET /users", Attributes: []Attribute{
+			{Name: "error.type", Type: "string", Values: []string{"timeout"}},
+			{Name: "http.request.method", Type: "string", Values: []string{"GET", "HEAD"}},
+			{Name: "http.response.status_code", Type: "int"},
+		}},
+	}}, r.Golden())
+}
+
+func TestGoldenReadWrite(t *testing.T) {
+	r := newTestRecorder(t)
+	r.Observe(span("GET /users", stringAttr("http.request.method", "GET")))
+	want := r.Golden()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, want.Write(buf))
+	path := filepath.Join(t.TempDir(), "golden.yaml")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+	got, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	_, err = Read(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "golden: ")
+}
+
+func TestRecorderDiff(t *testing.T) {
+	before := newTestRecorder(t)
+	before.Observe(span("GET /users",
+		stringAttr("http.request.method", "GET"),
+		intAttr("http.response.status_code", 200),
+		stringAttr("net.peer.name", "a"),
+	))
+	before.Observe(span("GET /old"))
+	golden := before.Golden()
+
+	after := newTestRecorder(t)
+	after.Observe(span("GET /users",
+		stringAttr("http.request.method", "POST"),
+		stringAttr("http.response.status_code", "200"),
+		stringAttr("server.address", "a"),
+	))
+	after.Observe(span("GET /new"))
+
+	item := func(name, attribute string, kind ChangeKind, want, got string) Change {
+		return Change{Signal: "trace", Service: "api", Scope: "otelhttp", Name: name, Attribute: attribute, Kind: kind, Golden: want, Got: got}
+	}
+	assert.Equal(t, []Change{
+		item("GET /new", "", ItemAdded, "", ""),
+		item("GET /old", "", ItemRemoved, "", ""),
+		item("GET /users", "http.request.method", ValueAdded, "", "POST"),
+		item("GET /users", "http.request.method", ValueRemoved, "GET", ""),
+		item("GET /users", "http.response.status_code", TypeChanged, "int", "string"),
+		item("GET /users", "net.peer.name", Removed, "", ""),
+		item("GET /users", "server.address", Added, "", ""),
+	}, after.Diff(golden))
+
+	assert.Empty(t, before.Diff(golden))
+	assert.Equal(t, `trace api otelhttp "GET /users" http.response.status_code: type-changed int -> string`, after.Diff(golden)[4].String())
+}
+
+func TestRecorderDiffTypes(t *testing.T) {
+	kvlist := &v1.KeyValue{Key: "app.labels", Value: &v1.AnyValue{Value: &v1.AnyValue_KvlistValue{KvlistValue: &v1.KeyValueList{}}}}
+	empty := &v1.KeyValue{Key: "app.ids", Value: &v1.AnyValue{Value: &v1.AnyValue_ArrayValue{ArrayValue: &v1.ArrayValue{}}}}
+	before := newTestRecorder(t)
+	before.Observe(span("GET /", kvlist, empty, intAttr("app.count", 1)))
+	golden := before.Golden()
+
+	after := newTestRecorder(t)
+	after.Observe(span("GET /", stringAttr("app.labels", "a=b"), &v1.KeyValue{Key: "app.ids", Value: &v1.AnyValue{Value: &v1.AnyValue_ArrayValue{
+		ArrayValue: &v1.ArrayValue{Values: []*v1.AnyValue{{Value: &v1.AnyValue_IntValue{IntValue: 1}}}},
+	}}}, intAttr("app.count", 1)))
+	after.Observe(span("GET /", stringAttr("app.count", "2")))
+
+	item := func(attribute, want, got string) Change {
+		return Change{Signal: "trace", Service: "api", Scope: "otelhttp", Name: "GET /", Attribute: attribute, Kind: TypeChanged, Golden: want, Got: got}
+	}
+	assert.Equal(t, []Change{
+		item("app.count", "int", "int|string"),
+		item("app.ids", "array", "int[]"),
+		item("app.labels", "map", "string"),
+	}, after.Diff(golden))
+	assert.Equal(t, "int|string", after.Golden().Items[0].Attributes[0].Type, "later samples of another type are a conflict")
+}
+
+func TestRecorderValuesOrder(t *testing.T) {
+	values := make([]*v1.KeyValue, 0, maxValues+10)
+	for i := 0; i < maxValues+10; i++ {
+		values = append(values, stringAttr("http.request.method", fmt.Sprintf("M%03d", i)))
+	}
+
+	forward := newTestRecorder(t)
+	for _, v := range values {
+		forward.Observe(span("GET /", v))
+	}
+	backward := newTestRecorder(t)
+	for i := len(values) - 1; i >= 0; i-- {
+		backward.Observe(span("GET /", values[i]))
+	}
+
+	golden := forward.Golden()
+	assert.Equal(t, golden, backward.Golden())
+	got := golden.Items[0].Attributes[0].Values
+	assert.Len(t, got, maxValues)
+	assert.Equal(t, "M000", got[0])
+	assert.Equal(t, fmt.Sprintf("M%03d", maxValues-1), got[maxValues-1])
+	assert.Empty(t, backward.Diff(golden), "the kept values don't depend on the order")
+}
+
+func TestRecorderLogNames(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+	r := newTestRecorder(t)
+	c, err := check.New(servers.Config{}, svs, r)
+	require.NoError(t, err)
+
+	event := `{"key":"event.name","value":{"string_value":"app.start"}}`
+	_, err = c.Check(context.Background(), strings.NewReader(`{"resource_logs":[{"scope_logs":[{"log_records":[`+
+		`{"body":{"string_value":"hello"},"attributes":[`+event+`]},`+
+		`{"body":{"string_value":"hello again"},"attributes":[`+event+`]},`+
+		`{"body":{"kvlist_value":{"values":[{"key":"a","value":{"int_value":"1"}}]}}}]}]}]}`))
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, item := range r.Golden().Items {
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{"", "app.start"}, names, "log records are named by their event.name, not their body")
+}
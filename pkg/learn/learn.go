@@ -0,0 +1,381 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package learn infers the semantic convention groups of the telemetry
+// services send, to suggest a config for a new service.
+package learn
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// maxNames is the number of span names a match lists in its regex, spans
+// with more names are matched by an attribute.
+const maxNames = 10
+
+// maxInferred bounds the cache of inferred groups, the keys are from
+// telemetry.
+const maxInferred = 10000
+
+// eventName is the attribute with the name of an event log record.
+const eventName = "event.name"
+
+// units name one and many items of a signal in the comments.
+var units = map[string][2]string{
+	"trace":   {"span", "spans"},
+	"metrics": {"data point", "data points"},
+	"log":     {"log record", "log records"},
+}
+
+// count is the number of items of the signal, e.g. 2 spans.
+func count(signal string, n int) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, units[signal][0])
+	}
+	return fmt.Sprintf("%d %s", n, units[signal][1])
+}
+
+// Learner records the groups the items most likely follow. It is a
+// servers.Observer.
+type Learner struct {
+	version semconv.SemanticVersion
+	// candidates are the groups an item of a signal can follow.
+	candidates map[string][]candidate
+
+	mu sync.Mutex
+	// inferred caches the group of an item by its signature.
+	inferred  map[string]string
+	learned   map[key]*learned
+	unmatched map[string]int
+}
+
+type key struct {
+	signal, group string
+}
+
+// learned is what was seen of the items of a group.
+type learned struct {
+	count int
+	// names are the span names, up to one more than maxNames.
+	names map[string]bool
+	// keys counts the items with each attribute key.
+	keys map[string]int
+}
+
+// candidate is a group with its attributes.
+type candidate struct {
+	group semconv.Group
+	attrs map[string]bool
+	// templates are the prefixes of the template attributes.
+	templates []string
+}
+
+func newCandidate(g semconv.Group) candidate {
+	c := candidate{group: g, attrs: map[string]bool{}}
+	for _, a := range g.Attributes {
+		if a.Type.IsTemplate() {
+			c.templates = append(c.templates, a.CanonicalId+".")
+			continue
+		}
+		c.attrs[a.CanonicalId] = true
+	}
+	return c
+}
+
+// has reports if the key is an attribute of the group.
+func (c candidate) has(key string) bool {
+	if c.attrs[key] {
+		return true
+	}
+	for _, t := range c.templates {
+		if strings.HasPrefix(key, t) {
+			return true
+		}
+	}
+	return false
+}
+
+var _ servers.Observer = &Learner{}
+
+func New(version semconv.SemanticVersion) *Learner {
+	l := &Learner{
+		version:    version,
+		candidates: map[string][]candidate{},
+		inferred:   map[string]string{},
+		learned:    map[key]*learned{},
+		unmatched:  map[string]int{},
+	}
+	for _, g := range version.GroupsOfType("") {
+		switch g.Type {
+		case "span":
+			l.candidates["trace"] = append(l.candidates["trace"], newCandidate(g))
+		case "metric":
+			l.candidates["metrics"] = append(l.candidates["metrics"], newCandidate(g))
+		case "event":
+			l.candidates["log"] = append(l.candidates["log"], newCandidate(g))
+		}
+	}
+	return l
+}
+
+func (l *Learner) Observe(item servers.Item) {
+	keys := attributeKeys(item.Attributes)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	group := l.infer(item, keys)
+	if group == "" {
+		l.unmatched[item.Signal]++
+		return
+	}
+	k := key{item.Signal, group}
+	lr, ok := l.learned[k]
+	if !ok {
+		lr = &learned{names: map[string]bool{}, keys: map[string]int{}}
+		l.learned[k] = lr
+	}
+	lr.count++
+	if item.Signal != "log" && len(lr.names) <= maxNames {
+		lr.names[item.Name] = true
+	}
+	for _, key := range keys {
+		lr.keys[key]++
+	}
+}
+
+func attributeKeys(attrs []*v1.KeyValue) []string {
+	keys := make([]string, 0, len(attrs))
+	for _, a := range attrs {
+		keys = append(keys, a.GetKey())
+	}
+	sort.Strings(keys)
+	return slices.Compact(keys)
+}
+
+// infer returns the group the item most likely follows, empty if none. A
+// metric follows the group of its name, and a log record the event group of
+// its event.name. Otherwise it is the group of the same span kind with the
+// most of the item's attributes, then with the most of its attributes on the
+// item.
+func (l *Learner) infer(item servers.Item, keys []string) string {
+	event := ""
+	for _, a := range item.Attributes {
+		if a.GetKey() == eventName {
+			event = a.GetValue().GetStringValue()
+		}
+	}
+	signature := strings.Join(append([]string{item.Signal, item.Kind, event, metricName(item)}, keys...), "\x00")
+	if group, ok := l.inferred[signature]; ok {
+		return group
+	}
+
+	best, bestHits, bestRatio := "", 0, 0.0
+	for _, c := range l.candidates[item.Signal] {
+		switch {
+		case item.Signal == "metrics":
+			if c.group.MetricName == item.Name {
+				best = c.group.Id
+			}
+			continue
+		case event != "" && c.group.Name == event:
+			best, bestHits = c.group.Id, len(keys)+1
+			continue
+		case c.group.SpanKind != "" && item.Kind != "" && c.group.SpanKind != item.Kind:
+			continue
+		}
+		hits := 0
+		for _, k := range keys {
+			if c.has(k) {
+				hits++
+			}
+		}
+		if hits == 0 {
+			continue
+		}
+		ratio := float64(hits) / float64(len(c.group.Attributes))
+		if hits > bestHits || (hits == bestHits && ratio > bestRatio) {
+			best, bestHits, bestRatio = c.group.Id, hits, ratio
+		}
+	}
+	if len(l.inferred) < maxInferred {
+		l.inferred[signature] = best
+	}
+	return best
+}
+
+func metricName(item servers.Item) string {
+	if item.Signal == "metrics" {
+		return item.Name
+	}
+	return ""
+}
+
+// Config is the suggested config, with the keys of servers.Config. The
+// resource isn't suggested, the servers don't check it.
+type Config struct {
+	ServerAddress string  `yaml:"server_address,omitempty"`
+	Trace         []Match `yaml:"trace,omitempty"`
+	Metrics       []Match `yaml:"metrics,omitempty"`
+	Log           []Match `yaml:"log,omitempty"`
+	// Unmatched counts the items of each signal that follow no group.
+	Unmatched map[string]int `yaml:"-"`
+}
+
+// Match is a suggested match, with the keys of servers.Match.
+type Match struct {
+	SemanticVersion  string      `yaml:"semantic_version,omitempty"`
+	Match            string      `yaml:"match,omitempty"`
+	MatchAttributes  []Attribute `yaml:"match_attributes,omitempty"`
+	Groups           []string    `yaml:"groups"`
+	Ignore           []string    `yaml:"ignore,omitempty"`
+	ReportAdditional bool        `yaml:"report_additional"`
+	// Note is written as a comment above the match.
+	Note string `yaml:"-"`
+}
+
+type Attribute struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value,omitempty"`
+}
+
+// Config returns the suggested config for what was observed. The matches of
+// a signal are sorted by group.
+func (l *Learner) Config() Config {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	keys := make([]key, 0, len(l.learned))
+	for k := range l.learned {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].group < keys[j].group })
+
+	cfg := Config{Unmatched: maps.Clone(l.unmatched)}
+	for _, k := range keys {
+		m := l.match(k, l.learned[k])
+		switch k.signal {
+		case "trace":
+			cfg.Trace = append(cfg.Trace, m)
+		case "metrics":
+			cfg.Metrics = append(cfg.Metrics, m)
+		case "log":
+			cfg.Log = append(cfg.Log, m)
+		}
+	}
+	return cfg
+}
+
+// semanticVersion is set on the matches unless it is the default.
+func (l *Learner) semanticVersion() string {
+	if l.version.Url == semconv.DefaultVersion {
+		return ""
+	}
+	return l.version.Url
+}
+
+// match selects the items of a group: metrics by name, spans by their names
+// when there are few, otherwise by an attribute of the group every item has.
+// Attributes that aren't in the group are ignored.
+func (l *Learner) match(k key, lr *learned) Match {
+	c := newCandidate(l.version.Groups[k.group])
+	m := Match{
+		SemanticVersion:  l.semanticVersion(),
+		Groups:           []string{k.group},
+		ReportAdditional: true,
+	}
+	seen := 0
+	for key := range lr.keys {
+		if c.has(key) {
+			seen++
+			continue
+		}
+		m.Ignore = append(m.Ignore, key)
+	}
+	sort.Strings(m.Ignore)
+	m.Note = fmt.Sprintf("%s, %d of the %d attributes of the group seen", count(k.signal, lr.count), seen, len(c.group.Attributes))
+
+	names := make([]string, 0, len(lr.names))
+	for name := range lr.names {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	sort.Strings(names)
+	switch {
+	case k.signal == "metrics":
+		m.Match = "^" + regexp.QuoteMeta(c.group.MetricName) + "$"
+	case k.signal == "log" && c.group.Name != "" && lr.keys[eventName] == lr.count:
+		m.MatchAttributes = []Attribute{{Name: eventName, Value: c.group.Name}}
+	case k.signal == "trace" && len(names) <= maxNames:
+		m.Match = "^(" + strings.Join(names, "|") + ")$"
+	default:
+		if name := selector(c.group, lr); name != "" {
+			m.MatchAttributes = []Attribute{{Name: name}}
+		} else {
+			m.Note += ", selects every item"
+		}
+	}
+	return m
+}
+
+// selector is an attribute of the group every item has, required ones
+// first, then by id.
+func selector(g semconv.Group, lr *learned) string {
+	attrs := slices.Clone(g.Attributes)
+	sort.Slice(attrs, func(i, j int) bool {
+		ri, rj := attrs[i].Level() == semconv.Required, attrs[j].Level() == semconv.Required
+		if ri != rj {
+			return ri
+		}
+		return attrs[i].CanonicalId < attrs[j].CanonicalId
+	})
+	for _, a := range attrs {
+		if lr.keys[a.CanonicalId] == lr.count {
+			return a.CanonicalId
+		}
+	}
+	return ""
+}
+
+// Write writes the config as YAML, with the notes as comments.
+func (c Config) Write(w io.Writer) error {
+	doc := &yaml.Node{}
+	if err := doc.Encode(c); err != nil {
+		return err
+	}
+	doc.HeadComment = "Suggested from the received telemetry, review before use."
+	for _, signal := range []string{"trace", "metrics", "log"} {
+		if n := c.Unmatched[signal]; n > 0 {
+			doc.HeadComment += fmt.Sprintf("\n%s followed no group.", count(signal, n))
+		}
+	}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		var matches []Match
+		switch doc.Content[i].Value {
+		case "trace":
+			matches = c.Trace
+		case "metrics":
+			matches = c.Metrics
+		case "log":
+			matches = c.Log
+		}
+		for j, m := range matches {
+			doc.Content[i+1].Content[j].HeadComment = m.Note
+		}
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
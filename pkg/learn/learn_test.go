@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package learn
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+const version = "https://opentelemetry.io/schemas/1.24.0"
+
+func attrs(kv ...string) []*v1.KeyValue {
+	out := []*v1.KeyValue{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		out = append(out, &v1.KeyValue{Key: kv[i], Value: &v1.AnyValue{Value: &v1.AnyValue_StringValue{StringValue: kv[i+1]}}})
+	}
+	return out
+}
+
+func newTestLearner(t *testing.T) (*Learner, map[string]semconv.SemanticVersion) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+	l := New(svs[version])
+
+	resource := attrs("service.name", "api", "host.name", "a", "deploy.ring", "canary")
+	for _, route := range []string{"/users/{id}", "/orders"} {
+		l.Observe(servers.Item{Signal: "trace", Name: "GET " + route, Kind: "server", Resource: resource, Attributes: attrs(
+			"http.request.method", "GET", "http.route", route, "url.scheme", "https", "app.tenant", "a",
+		)})
+	}
+	l.Observe(servers.Item{Signal: "trace", Name: "GET", Kind: "client", Resource: resource, Attributes: attrs(
+		"http.request.method", "GET", "server.address", "b", "url.full", "https://b/",
+	)})
+	l.Observe(servers.Item{Signal: "trace", Name: "work", Kind: "internal", Resource: resource, Attributes: attrs("app.job", "a")})
+	l.Observe(servers.Item{Signal: "metrics", Name: "http.server.request.duration", Resource: resource, Attributes: attrs(
+		"http.request.method", "GET", "http.route", "/orders",
+	)})
+	l.Observe(servers.Item{Signal: "log", Name: "active", Resource: resource, Attributes: attrs(
+		"event.name", "device.app.lifecycle", "ios.state", "active",
+	)})
+	return l, svs
+}
+
+func TestLearnerConfig(t *testing.T) {
+	l, _ := newTestLearner(t)
+	cfg := l.Config()
+
+	require.Len(t, cfg.Trace, 2)
+	assert.Equal(t, []string{"trace.http.client"}, cfg.Trace[0].Groups)
+	assert.Equal(t, `^(GET)$`, cfg.Trace[0].Match)
+	assert.Equal(t, []string{"trace.http.server"}, cfg.Trace[1].Groups)
+	assert.Equal(t, `^(GET /orders|GET /users/\{id\})$`, cfg.Trace[1].Match)
+	assert.Equal(t, []string{"app.tenant"}, cfg.Trace[1].Ignore)
+	assert.Equal(t, version, cfg.Trace[1].SemanticVersion)
+	assert.True(t, cfg.Trace[1].ReportAdditional)
+
+	require.Len(t, cfg.Metrics, 1)
+	assert.Equal(t, []string{"metric.http.server.request.duration"}, cfg.Metrics[0].Groups)
+	assert.Equal(t, `^http\.server\.request\.duration$`, cfg.Metrics[0].Match)
+
+	require.Len(t, cfg.Log, 1)
+	assert.Equal(t, []Attribute{{Name: "event.name", Value: "device.app.lifecycle"}}, cfg.Log[0].MatchAttributes)
+
+	assert.Equal(t, map[string]int{"trace": 1}, cfg.Unmatched)
+}
+
+func TestLearnerManyNames(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+	l := New(svs[version])
+	for i := 0; i <= maxNames; i++ {
+		l.Observe(servers.Item{Signal: "trace", Name: fmt.Sprintf("GET /%d", i), Kind: "server", Attributes: attrs(
+			"http.request.method", "GET", "url.scheme", "https",
+		)})
+	}
+
+	cfg := l.Config()
+	require.Len(t, cfg.Trace, 1)
+	assert.Empty(t, cfg.Trace[0].Match)
+	assert.Equal(t, []Attribute{{Name: "http.request.method"}}, cfg.Trace[0].MatchAttributes, "a required attribute every span has")
+}
+
+func TestConfigWrite(t *testing.T) {
+	l, svs := newTestLearner(t)
+	buf := &bytes.Buffer{}
+	learned := l.Config()
+	learned.ServerAddress = "localhost:4317"
+	require.NoError(t, learned.Write(buf))
+	assert.Contains(t, buf.String(), "# 2 spans, 3 of the")
+	assert.Contains(t, buf.String(), "# 1 span followed no group.")
+	assert.NotContains(t, buf.String(), "resource:", "resource matches aren't checked")
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(buf))
+	cfg := servers.Config{}
+	require.NoError(t, v.Unmarshal(&cfg))
+	assert.NoError(t, cfg.Validate(svs))
+	assert.Len(t, cfg.Trace, 2)
+	assert.Equal(t, []string{"app.tenant"}, cfg.Trace[1].Ignore)
+}
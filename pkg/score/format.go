@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package score
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Formats are the names accepted by Write.
+var Formats = []string{"table", "markdown", "json"}
+
+// Write writes the report in the format, one of Formats.
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "table", "":
+		return r.WriteTable(w)
+	case "markdown":
+		return r.WriteMarkdown(w)
+	case "json":
+		return r.WriteJSON(w)
+	}
+	return fmt.Errorf("unknown report format %q, expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+func (r Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANK\tSERVICE\tSCOPE\tITEMS\tREQUIRED\tRECOMMENDED\tSCORE")
+	for _, s := range r.Scopes {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\t%s\t%s\n", s.Rank, s.Service, scopeName(s), s.Items, s.Required, s.Recommended, percent(s.Score))
+	}
+	return tw.Flush()
+}
+
+func (r Report) WriteMarkdown(w io.Writer) error {
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "| Rank | Service | Scope | Items | Required | Recommended | Score |")
+	fmt.Fprintln(b, "| ---: | --- | --- | ---: | ---: | ---: | ---: |")
+	for _, s := range r.Scopes {
+		fmt.Fprintf(b, "| %d | %s | %s | %d | %s | %s | %s |\n", s.Rank, escape(s.Service), escape(scopeName(s)), s.Items, s.Required, s.Recommended, percent(s.Score))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// String formats the score as e.g. "83% (5/6)".
+func (s Score) String() string {
+	if s.Expected == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%d/%d)", percent(s.Ratio), s.Present, s.Expected)
+}
+
+func percent(ratio float64) string {
+	return fmt.Sprintf("%.0f%%", ratio*100)
+}
+
+func scopeName(s ScopeScore) string {
+	if s.ScopeVersion == "" {
+		return s.Scope
+	}
+	return s.Scope + " " + s.ScopeVersion
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
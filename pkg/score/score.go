@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package score rates how well each service and instrumentation scope follows
+// the semantic conventions, by the fraction of required and recommended
+// attributes of the matched groups that are present.
+package score
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+)
+
+// Scorecard collects the scores of the checked items. It is a
+// servers.Observer.
+type Scorecard struct {
+	mu     sync.Mutex
+	scopes map[scopeKey]*scopeCounts
+}
+
+type scopeKey struct {
+	service string
+	scope   string
+	version string
+}
+
+type scopeCounts struct {
+	items  int
+	groups map[string]*groupCounts
+}
+
+type groupCounts struct {
+	required    Score
+	recommended Score
+}
+
+var _ servers.Observer = &Scorecard{}
+
+func New() *Scorecard {
+	return &Scorecard{scopes: map[scopeKey]*scopeCounts{}}
+}
+
+func (s *Scorecard) Observe(item servers.Item) {
+	if !item.Matched {
+		return
+	}
+	missing := map[[2]string]bool{}
+	for _, f := range item.Findings {
+		switch f.Category {
+		case servers.TypeMismatch, servers.ValueMismatch, servers.HighCardinality, servers.SpanNameMismatch, servers.Naming, servers.UnknownAttribute, servers.Extra:
+		default:
+			missing[[2]string{f.Group, f.Attribute}] = true
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := scopeKey{item.Service, item.Scope, item.ScopeVersion}
+	sc := s.scopes[key]
+	if sc == nil {
+		sc = &scopeCounts{groups: map[string]*groupCounts{}}
+		s.scopes[key] = sc
+	}
+	sc.items++
+	for _, e := range item.Expected {
+		var score *Score
+		g := sc.groups[e.Group]
+		if g == nil {
+			g = &groupCounts{}
+			sc.groups[e.Group] = g
+		}
+		switch e.Level {
+		case semconv.Required:
+			score = &g.required
+		case semconv.Recommended:
+			score = &g.recommended
+		default:
+			// Conditionally required and opt-in attributes depend on things
+			// the checker can't see, so they aren't scored.
+			continue
+		}
+		score.Expected++
+		if !missing[[2]string{e.Group, e.Attribute}] {
+			score.Present++
+		}
+	}
+}
+
+// Report ranks the scopes from the most to the least compliant.
+func (s *Scorecard) Report() Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := Report{Scopes: []ScopeScore{}}
+	for key, sc := range s.scopes {
+		scope := ScopeScore{
+			Service:      key.service,
+			Scope:        key.scope,
+			ScopeVersion: key.version,
+			Items:        sc.items,
+			Groups:       []GroupScore{},
+		}
+		for name, g := range sc.groups {
+			scope.Groups = append(scope.Groups, GroupScore{
+				Group:       name,
+				Required:    g.required.withRatio(),
+				Recommended: g.recommended.withRatio(),
+			})
+			scope.Required = scope.Required.add(g.required)
+			scope.Recommended = scope.Recommended.add(g.recommended)
+		}
+		sort.Slice(scope.Groups, func(i, j int) bool { return scope.Groups[i].Group < scope.Groups[j].Group })
+		scope.Required = scope.Required.withRatio()
+		scope.Recommended = scope.Recommended.withRatio()
+		scope.Score = scope.Required.add(scope.Recommended).withRatio().Ratio
+		report.Scopes = append(report.Scopes, scope)
+	}
+
+	sort.Slice(report.Scopes, func(i, j int) bool {
+		a, b := report.Scopes[i], report.Scopes[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Scope != b.Scope {
+			return a.Scope < b.Scope
+		}
+		return a.ScopeVersion < b.ScopeVersion
+	})
+	for i := range report.Scopes {
+		report.Scopes[i].Rank = i + 1
+	}
+	return report
+}
+
+// Report is the scores of every observed service and scope.
+type Report struct {
+	Scopes []ScopeScore `json:"scopes"`
+}
+
+// ScopeScore is the score of an instrumentation scope in a service.
+type ScopeScore struct {
+	Rank         int    `json:"rank"`
+	Service      string `json:"service"`
+	Scope        string `json:"scope"`
+	ScopeVersion string `json:"scope_version"`
+	// Items is the number of checked spans, data points and log records.
+	Items       int   `json:"items"`
+	Required    Score `json:"required"`
+	Recommended Score `json:"recommended"`
+	// Score is the ratio of required and recommended attributes present.
+	Score  float64      `json:"score"`
+	Groups []GroupScore `json:"groups"`
+}
+
+// GroupScore is the score of a semantic convention group within a scope.
+type GroupScore struct {
+	Group       string `json:"group"`
+	Required    Score  `json:"required"`
+	Recommended Score  `json:"recommended"`
+}
+
+// Score counts the expected attributes, over every checked item, and how many
+// of them were present.
+type Score struct {
+	Present  int `json:"present"`
+	Expected int `json:"expected"`
+	// Ratio is Present / Expected, 1 when nothing is expected.
+	Ratio float64 `json:"ratio"`
+}
+
+func (s Score) add(o Score) Score {
+	return Score{Present: s.Present + o.Present, Expected: s.Expected + o.Expected}
+}
+
+func (s Score) withRatio() Score {
+	s.Ratio = 1
+	if s.Expected > 0 {
+		s.Ratio = float64(s.Present) / float64(s.Expected)
+	}
+	return s
+}
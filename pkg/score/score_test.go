@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package score
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var httpExpected = []servers.Expected{
+	{Group: "trace.http.server", Attribute: "http.request.method", Level: semconv.Required},
+	{Group: "trace.http.server", Attribute: "url.scheme", Level: semconv.Required},
+	{Group: "trace.http.server", Attribute: "http.route", Level: semconv.Recommended},
+	{Group: "trace.http.server", Attribute: "server.port", Level: semconv.ConditionallyRequired},
+}
+
+func newTestScorecard() *Scorecard {
+	s := New()
+	s.Observe(servers.Item{
+		Service: "api", Scope: "otelhttp", ScopeVersion: "v0.45.0", Matched: true,
+		Expected: httpExpected,
+		Findings: []servers.Finding{
+			{Group: "trace.http.server", Attribute: "url.scheme", Category: servers.RequiredMissing},
+			{Group: "trace.http.server", Attribute: "http.route", Category: servers.RecommendedMissing},
+		},
+	})
+	s.Observe(servers.Item{
+		Service: "api", Scope: "otelhttp", ScopeVersion: "v0.45.0", Matched: true,
+		Expected: httpExpected,
+		Findings: []servers.Finding{
+			{Group: "trace.http.server", Attribute: "http.request.method", Category: servers.TypeMismatch},
+		},
+	})
+	s.Observe(servers.Item{
+		Service: "web", Scope: "otelhttp", ScopeVersion: "v0.46.0", Matched: true,
+		Expected: httpExpected,
+	})
+	s.Observe(servers.Item{Service: "web", Scope: "otelhttp", ScopeVersion: "v0.46.0"})
+	return s
+}
+
+func TestScorecardReport(t *testing.T) {
+	r := newTestScorecard().Report()
+	require.Len(t, r.Scopes, 2)
+
+	web, api := r.Scopes[0], r.Scopes[1]
+	assert.Equal(t, 1, web.Rank)
+	assert.Equal(t, "web", web.Service)
+	assert.Equal(t, 1, web.Items, "unmatched items aren't scored")
+	assert.Equal(t, 1.0, web.Score)
+
+	assert.Equal(t, 2, api.Rank)
+	assert.Equal(t, 2, api.Items)
+	assert.Equal(t, Score{Present: 3, Expected: 4, Ratio: 0.75}, api.Required)
+	assert.Equal(t, Score{Present: 1, Expected: 2, Ratio: 0.5}, api.Recommended)
+	assert.InDelta(t, 4.0/6.0, api.Score, 0.0001)
+	require.Len(t, api.Groups, 1)
+	assert.Equal(t, "trace.http.server", api.Groups[0].Group)
+}
+
+func TestReportWrite(t *testing.T) {
+	r := newTestScorecard().Report()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, r.Write(buf, "table"))
+	assert.Contains(t, buf.String(), "otelhttp v0.45.0")
+	assert.Contains(t, buf.String(), "75% (3/4)")
+
+	buf.Reset()
+	require.NoError(t, r.Write(buf, "markdown"))
+	assert.Contains(t, buf.String(), "| 2 | api | otelhttp v0.45.0 | 2 | 75% (3/4) | 50% (1/2) | 67% |")
+
+	buf.Reset()
+	require.NoError(t, r.Write(buf, "json"))
+	got := Report{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, r, got)
+
+	assert.EqualError(t, r.Write(buf, "csv"), `unknown report format "csv", expected one of table, markdown, json`)
+}
@@ -0,0 +1,353 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package selftelemetry exports the findings of the checker as OTLP logs and
+// its compliance statistics as OTLP metrics, so they can be viewed next to
+// the telemetry that caused them.
+package selftelemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	pbCollectorLogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	pbCollectorMetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+	pbLogs "go.opentelemetry.io/proto/otlp/logs/v1"
+	pbMetrics "go.opentelemetry.io/proto/otlp/metrics/v1"
+	pbResource "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	scopeName       = "github.com/madvikinggod/otel-semconv-checker/pkg/selftelemetry"
+	defaultInterval = 10 * time.Second
+	// seenLimit bounds the keys of exported findings, the keys are from
+	// telemetry. The keys are forgotten when it is reached, so a finding
+	// may be exported again.
+	seenLimit = 10000
+)
+
+// resource identifies the checker as the source of the exported telemetry.
+var resource = &pbResource.Resource{
+	Attributes: []*pbCommon.KeyValue{
+		stringKV("service.name", "otel-semconv-checker"),
+	},
+}
+
+// Exporter is a servers.Observer that exports a log record the first time a
+// finding is seen, and the number of checked and compliant items every
+// interval.
+type Exporter struct {
+	conn     *grpc.ClientConn
+	logs     pbCollectorLogs.LogsServiceClient
+	metrics  pbCollectorMetrics.MetricsServiceClient
+	interval time.Duration
+	start    time.Time
+
+	mu sync.Mutex
+	// seen holds the keys of findings already exported, up to seenLimit.
+	seen    map[string]bool
+	records []*pbLogs.LogRecord
+	counts  map[countKey]*count
+}
+
+type countKey struct {
+	signal  string
+	service string
+}
+
+type count struct {
+	checked   int64
+	compliant int64
+	unmatched int64
+}
+
+var _ servers.Observer = &Exporter{}
+
+// New creates an exporter for the config. Nothing is sent until Run is called.
+func New(cfg servers.SelfTelemetry) (*Exporter, error) {
+	creds := credentials.NewTLS(&tls.Config{})
+	if cfg.Insecure {
+		creds = insecure.NewCredentials()
+	}
+	conn, err := grpc.Dial(cfg.Endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("self_telemetry: %w", err)
+	}
+	return newExporter(conn, cfg.Interval), nil
+}
+
+func newExporter(conn *grpc.ClientConn, interval time.Duration) *Exporter {
+	if interval == 0 {
+		interval = defaultInterval
+	}
+	return &Exporter{
+		conn:     conn,
+		logs:     pbCollectorLogs.NewLogsServiceClient(conn),
+		metrics:  pbCollectorMetrics.NewMetricsServiceClient(conn),
+		interval: interval,
+		start:    time.Now(),
+		seen:     map[string]bool{},
+		counts:   map[countKey]*count{},
+	}
+}
+
+func (e *Exporter) Observe(item servers.Item) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c := e.counts[countKey{item.Signal, item.Service}]
+	if c == nil {
+		c = &count{}
+		e.counts[countKey{item.Signal, item.Service}] = c
+	}
+	if !item.Matched {
+		c.unmatched++
+		return
+	}
+	c.checked++
+	if item.Compliant() {
+		c.compliant++
+	}
+
+	for _, record := range newRecords(item) {
+		if e.seen[record.key] {
+			continue
+		}
+		if len(e.seen) >= seenLimit {
+			clear(e.seen)
+		}
+		e.seen[record.key] = true
+		e.records = append(e.records, record.LogRecord)
+	}
+}
+
+// Run exports every interval until ctx is done, then exports once more and
+// closes the connection.
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.export(ctx)
+		case <-ctx.Done():
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			e.export(ctx)
+			cancel()
+			_ = e.conn.Close()
+			return
+		}
+	}
+}
+
+// export sends the new log records and the current counts. Errors are logged,
+// the records of a failed export are dropped.
+func (e *Exporter) export(ctx context.Context) {
+	e.mu.Lock()
+	records := e.records
+	e.records = nil
+	metrics := e.metricData(time.Now())
+	e.mu.Unlock()
+
+	if len(records) > 0 {
+		_, err := e.logs.Export(ctx, &pbCollectorLogs.ExportLogsServiceRequest{
+			ResourceLogs: []*pbLogs.ResourceLogs{{
+				Resource: resource,
+				ScopeLogs: []*pbLogs.ScopeLogs{{
+					Scope:      &pbCommon.InstrumentationScope{Name: scopeName},
+					LogRecords: records,
+				}},
+			}},
+		})
+		if err != nil {
+			slog.Error("failed to export findings", "records", len(records), "error", err)
+		}
+	}
+
+	if len(metrics) > 0 {
+		_, err := e.metrics.Export(ctx, &pbCollectorMetrics.ExportMetricsServiceRequest{
+			ResourceMetrics: []*pbMetrics.ResourceMetrics{{
+				Resource: resource,
+				ScopeMetrics: []*pbMetrics.ScopeMetrics{{
+					Scope:   &pbCommon.InstrumentationScope{Name: scopeName},
+					Metrics: metrics,
+				}},
+			}},
+		})
+		if err != nil {
+			slog.Error("failed to export compliance metrics", "error", err)
+		}
+	}
+}
+
+// metricData builds cumulative sums of the counts. It must be called with mu
+// held.
+func (e *Exporter) metricData(now time.Time) []*pbMetrics.Metric {
+	if len(e.counts) == 0 {
+		return nil
+	}
+	keys := make([]countKey, 0, len(e.counts))
+	for k := range e.counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].signal != keys[j].signal {
+			return keys[i].signal < keys[j].signal
+		}
+		return keys[i].service < keys[j].service
+	})
+
+	sum := func(name, description string, value func(*count) int64) *pbMetrics.Metric {
+		points := []*pbMetrics.NumberDataPoint{}
+		for _, k := range keys {
+			points = append(points, &pbMetrics.NumberDataPoint{
+				Attributes: []*pbCommon.KeyValue{
+					stringKV("semconv_checker.signal", k.signal),
+					stringKV("service.name", k.service),
+				},
+				StartTimeUnixNano: uint64(e.start.UnixNano()),
+				TimeUnixNano:      uint64(now.UnixNano()),
+				Value:             &pbMetrics.NumberDataPoint_AsInt{AsInt: value(e.counts[k])},
+			})
+		}
+		return &pbMetrics.Metric{
+			Name:        name,
+			Description: description,
+			Unit:        "{item}",
+			Data: &pbMetrics.Metric_Sum{Sum: &pbMetrics.Sum{
+				DataPoints:             points,
+				AggregationTemporality: pbMetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				IsMonotonic:            true,
+			}},
+		}
+	}
+
+	return []*pbMetrics.Metric{
+		sum("semconv_checker.items.checked", "Items matched by at least one match and checked.", func(c *count) int64 { return c.checked }),
+		sum("semconv_checker.items.compliant", "Checked items with no findings other than extra attributes.", func(c *count) int64 { return c.compliant }),
+		sum("semconv_checker.items.unmatched", "Items not matched by any match.", func(c *count) int64 { return c.unmatched }),
+	}
+}
+
+type record struct {
+	*pbLogs.LogRecord
+	// key identifies the finding, so it is only exported once.
+	key string
+}
+
+// newRecords builds a record for each group with findings in the item. Extra
+// attributes aren't from a group, so they have a record without one.
+func newRecords(item servers.Item) []record {
+	byGroup := map[string]map[servers.Category][]string{}
+	for _, f := range item.Findings {
+		if byGroup[f.Group] == nil {
+			byGroup[f.Group] = map[servers.Category][]string{}
+		}
+		byGroup[f.Group][f.Category] = append(byGroup[f.Group][f.Category], f.Attribute)
+	}
+	groups := make([]string, 0, len(byGroup))
+	for g := range byGroup {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+
+	now := uint64(time.Now().UnixNano())
+	records := []record{}
+	for _, group := range groups {
+		attrs := []*pbCommon.KeyValue{
+			stringKV("service.name", item.Service),
+			stringKV("otel.scope.name", item.Scope),
+			stringKV("otel.scope.version", item.ScopeVersion),
+			stringKV("semconv_checker.signal", item.Signal),
+			stringKV("semconv_checker.name", item.Name),
+		}
+		if group != "" {
+			attrs = append(attrs, stringKV("semconv_checker.group", group))
+		}
+		key := []string{item.Signal, item.Service, item.Scope, item.ScopeVersion, item.Name, group}
+
+		var missing, mismatched, invalid, high, name, naming, unknown, extra []string
+		for category, names := range byGroup[group] {
+			sort.Strings(names)
+			switch category {
+			case servers.TypeMismatch:
+				mismatched = names
+			case servers.ValueMismatch:
+				invalid = names
+			case servers.HighCardinality:
+				high = names
+			case servers.SpanNameMismatch:
+				name = names
+			case servers.Naming:
+				naming = names
+			case servers.UnknownAttribute:
+				unknown = names
+			case servers.Extra:
+				extra = names
+			default:
+				missing = append(missing, names...)
+			}
+		}
+		sort.Strings(missing)
+		for _, l := range []struct {
+			name  string
+			value []string
+		}{
+			{"semconv_checker.missing", missing},
+			{"semconv_checker.type_mismatch", mismatched},
+			{"semconv_checker.value_mismatch", invalid},
+			{"semconv_checker.high_cardinality", high},
+			{"semconv_checker.span_name", name},
+			{"semconv_checker.naming", naming},
+			{"semconv_checker.unknown_attribute", unknown},
+			{"semconv_checker.extra", extra},
+		} {
+			if len(l.value) == 0 {
+				continue
+			}
+			attrs = append(attrs, stringSliceKV(l.name, l.value))
+			key = append(key, l.name+"="+strings.Join(l.value, ","))
+		}
+
+		records = append(records, record{
+			LogRecord: &pbLogs.LogRecord{
+				TimeUnixNano:         now,
+				ObservedTimeUnixNano: now,
+				SeverityNumber:       pbLogs.SeverityNumber_SEVERITY_NUMBER_WARN,
+				SeverityText:         "WARN",
+				Body:                 &pbCommon.AnyValue{Value: &pbCommon.AnyValue_StringValue{StringValue: "semantic convention findings"}},
+				Attributes:           attrs,
+			},
+			key: strings.Join(key, "\x00"),
+		})
+	}
+	return records
+}
+
+func stringKV(key, value string) *pbCommon.KeyValue {
+	return &pbCommon.KeyValue{
+		Key:   key,
+		Value: &pbCommon.AnyValue{Value: &pbCommon.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+func stringSliceKV(key string, values []string) *pbCommon.KeyValue {
+	array := &pbCommon.ArrayValue{}
+	for _, v := range values {
+		array.Values = append(array.Values, &pbCommon.AnyValue{Value: &pbCommon.AnyValue_StringValue{StringValue: v}})
+	}
+	return &pbCommon.KeyValue{
+		Key:   key,
+		Value: &pbCommon.AnyValue{Value: &pbCommon.AnyValue_ArrayValue{ArrayValue: array}},
+	}
+}
@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package selftelemetry
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pbCollectorLogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	pbCollectorMetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	pbLogs "go.opentelemetry.io/proto/otlp/logs/v1"
+	pbMetrics "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type collector struct {
+	pbCollectorLogs.UnimplementedLogsServiceServer
+	pbCollectorMetrics.UnimplementedMetricsServiceServer
+
+	mu      sync.Mutex
+	records []*pbLogs.LogRecord
+	metrics []*pbMetrics.Metric
+}
+
+func (c *collector) Export(_ context.Context, req *pbCollectorLogs.ExportLogsServiceRequest) (*pbCollectorLogs.ExportLogsServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rl := range req.ResourceLogs {
+		for _, sl := range rl.ScopeLogs {
+			c.records = append(c.records, sl.LogRecords...)
+		}
+	}
+	return &pbCollectorLogs.ExportLogsServiceResponse{}, nil
+}
+
+type metricsCollector struct{ *collector }
+
+func (c metricsCollector) Export(_ context.Context, req *pbCollectorMetrics.ExportMetricsServiceRequest) (*pbCollectorMetrics.ExportMetricsServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rm := range req.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			c.metrics = append(c.metrics, sm.Metrics...)
+		}
+	}
+	return &pbCollectorMetrics.ExportMetricsServiceResponse{}, nil
+}
+
+func newTestExporter(t *testing.T) (*Exporter, *collector) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	c := &collector{}
+	srv := grpc.NewServer()
+	pbCollectorLogs.RegisterLogsServiceServer(srv, c)
+	pbCollectorMetrics.RegisterMetricsServiceServer(srv, metricsCollector{c})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return newExporter(conn, 0), c
+}
+
+func TestExporterRecordsNewFindings(t *testing.T) {
+	e, c := newTestExporter(t)
+
+	item := servers.Item{
+		Signal:  "trace",
+		Service: "api",
+		Scope:   "net/http",
+		Name:    "GET /users",
+		Matched: true,
+		Findings: []servers.Finding{
+			{Group: "trace.http.server", Attribute: "url.scheme", Category: servers.RequiredMissing},
+			{Group: "trace.http.server", Attribute: "http.route", Category: servers.RecommendedMissing},
+			{Attribute: "custom", Category: servers.Extra},
+		},
+	}
+	e.Observe(item)
+	e.Observe(item)
+	e.Observe(servers.Item{Signal: "trace", Service: "api", Matched: true})
+	e.Observe(servers.Item{Signal: "trace", Service: "api"})
+	e.export(context.Background())
+
+	require.Len(t, c.records, 2)
+	attrs := map[string]string{}
+	for _, kv := range c.records[1].Attributes {
+		attrs[kv.Key] = kv.Value.String()
+	}
+	assert.Contains(t, attrs["service.name"], "api")
+	assert.Contains(t, attrs["semconv_checker.group"], "trace.http.server")
+	assert.Contains(t, attrs["semconv_checker.missing"], "http.route")
+	assert.Contains(t, attrs["semconv_checker.missing"], "url.scheme")
+	assert.NotContains(t, attrs, "semconv_checker.extra")
+
+	values := map[string]int64{}
+	for _, m := range c.metrics {
+		values[m.Name] = m.GetSum().DataPoints[0].GetAsInt()
+	}
+	assert.Equal(t, map[string]int64{
+		"semconv_checker.items.checked":   3,
+		"semconv_checker.items.compliant": 1,
+		"semconv_checker.items.unmatched": 1,
+	}, values)
+
+	// Findings already exported aren't sent again.
+	e.Observe(item)
+	e.export(context.Background())
+	assert.Len(t, c.records, 2)
+}
+
+func TestExporterSeenLimit(t *testing.T) {
+	e, c := newTestExporter(t)
+	for i := 0; i < seenLimit; i++ {
+		e.seen[fmt.Sprint(i)] = true
+	}
+
+	e.Observe(servers.Item{Signal: "trace", Service: "api", Matched: true, Findings: []servers.Finding{
+		{Group: "trace.http.server", Attribute: "url.scheme", Category: servers.RequiredMissing},
+	}})
+	e.export(context.Background())
+	assert.Len(t, c.records, 1)
+	assert.Len(t, e.seen, 1, "the keys are forgotten at the limit")
+}
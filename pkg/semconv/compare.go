@@ -2,28 +2,106 @@
 
 package semconv
 
-import pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+import (
+	"sort"
+	"strings"
 
-func Compare(attrSlice []string, attributes ...[]*pbCommon.KeyValue) (missing []string, extra []string) {
-	attrs := map[string]bool{}
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+// Compare finds the expected attributes that are missing, the attributes that
+// were not expected, and the attributes whose value doesn't match the expected
+// type. Template attributes match every key with their prefix, and are
+// reported once by their template name.
+func Compare(expected []Attribute, attributes ...[]*pbCommon.KeyValue) (missing []string, extra []string, invalid []string) {
+	attrs := map[string]*pbCommon.KeyValue{}
 	for _, aList := range attributes {
 		for _, a := range aList {
-			attrs[a.Key] = false
+			attrs[a.Key] = a
 		}
 	}
-	for _, a := range attrSlice {
-		if _, ok := attrs[a]; !ok {
-			missing = append(missing, a)
-		} else {
-			attrs[a] = true
+	used := map[string]bool{}
+	seen := map[string]bool{}
+	for _, a := range expected {
+		if seen[a.CanonicalId] {
+			continue
+		}
+		seen[a.CanonicalId] = true
+
+		if a.Type.IsTemplate() {
+			found, valid := false, true
+			prefix := a.CanonicalId + "."
+			for key, kv := range attrs {
+				if !strings.HasPrefix(key, prefix) {
+					continue
+				}
+				found = true
+				used[key] = true
+				valid = valid && IsValidValue(a.Type.ValueType(), kv.GetValue())
+			}
+			if !found {
+				missing = append(missing, a.CanonicalId)
+			} else if !valid {
+				invalid = append(invalid, a.CanonicalId)
+			}
+			continue
+		}
+
+		kv, ok := attrs[a.CanonicalId]
+		if !ok {
+			missing = append(missing, a.CanonicalId)
+			continue
+		}
+		used[a.CanonicalId] = true
+		if !IsValidValue(a.Type.ValueType(), kv.GetValue()) {
+			invalid = append(invalid, a.CanonicalId)
 		}
 	}
-	for k, v := range attrs {
-		if !v {
+	for k := range attrs {
+		if !used[k] {
 			extra = append(extra, k)
 		}
 	}
-	return missing, extra
+	sort.Strings(extra)
+	return missing, extra, invalid
+}
+
+// IsValidValue reports if the value is of the semantic convention type, e.g.
+// string or int[]. Unknown types are always valid.
+func IsValidValue(typ string, value *pbCommon.AnyValue) bool {
+	if elem, ok := strings.CutSuffix(typ, "[]"); ok {
+		switch elem {
+		case "string", "int", "double", "boolean":
+		default:
+			return true
+		}
+		arr, ok := value.GetValue().(*pbCommon.AnyValue_ArrayValue)
+		if !ok {
+			return false
+		}
+		for _, v := range arr.ArrayValue.GetValues() {
+			if !IsValidValue(elem, v) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch typ {
+	case "string":
+		_, ok := value.GetValue().(*pbCommon.AnyValue_StringValue)
+		return ok
+	case "int":
+		_, ok := value.GetValue().(*pbCommon.AnyValue_IntValue)
+		return ok
+	case "double":
+		_, ok := value.GetValue().(*pbCommon.AnyValue_DoubleValue)
+		return ok
+	case "boolean":
+		_, ok := value.GetValue().(*pbCommon.AnyValue_BoolValue)
+		return ok
+	}
+	return true
 }
 
 func GetAttributes(groups ...Group) []string {
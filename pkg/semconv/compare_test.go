@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	pbCommon "go.opentelemetry.io/proto/otlp/common/v1"
 )
 
 // NOTE ALL THESE ARE DEPENDANT ON THE SEMCONV.  THEY MAY CHANGE WITH THE SEMCONV.
@@ -46,7 +47,6 @@ func TestGetAttributes(t *testing.T) {
 				"network.transport",
 				"network.type",
 				"user_agent.original",
-				"http.request.method",
 			},
 		},
 		{
@@ -81,3 +81,93 @@ func TestGetAttributes(t *testing.T) {
 		})
 	}
 }
+
+func TestCompare(t *testing.T) {
+	header := Attribute{CanonicalId: "http.request.header", Type: AttributeType{Name: "template[string[]]"}}
+	method := Attribute{CanonicalId: "http.request.method", Type: AttributeType{Name: "string"}}
+	port := Attribute{CanonicalId: "server.port", Type: AttributeType{Name: "int"}}
+
+	tests := []struct {
+		name        string
+		expected    []Attribute
+		attrs       []*pbCommon.KeyValue
+		wantMissing []string
+		wantExtra   []string
+		wantInvalid []string
+	}{
+		{
+			name:     "exact match",
+			expected: []Attribute{method},
+			attrs:    []*pbCommon.KeyValue{stringKV("http.request.method", "GET")},
+		},
+		{
+			name:        "missing and extra",
+			expected:    []Attribute{method},
+			attrs:       []*pbCommon.KeyValue{stringKV("http.method", "GET")},
+			wantMissing: []string{"http.request.method"},
+			wantExtra:   []string{"http.method"},
+		},
+		{
+			name:        "duplicate expected attributes are reported once",
+			expected:    []Attribute{method, method},
+			attrs:       []*pbCommon.KeyValue{},
+			wantMissing: []string{"http.request.method"},
+		},
+		{
+			name:     "template matches prefix",
+			expected: []Attribute{header},
+			attrs: []*pbCommon.KeyValue{
+				stringSliceKV("http.request.header.content_type", "text/plain"),
+				stringSliceKV("http.request.header.accept", "*/*"),
+			},
+		},
+		{
+			name:        "template missing",
+			expected:    []Attribute{header},
+			attrs:       []*pbCommon.KeyValue{stringSliceKV("http.request.header", "text/plain")},
+			wantMissing: []string{"http.request.header"},
+			wantExtra:   []string{"http.request.header"},
+		},
+		{
+			name:     "template wrong type",
+			expected: []Attribute{header},
+			attrs: []*pbCommon.KeyValue{
+				stringSliceKV("http.request.header.accept", "*/*"),
+				stringKV("http.request.header.content_type", "text/plain"),
+			},
+			wantInvalid: []string{"http.request.header"},
+		},
+		{
+			name:        "wrong type",
+			expected:    []Attribute{port},
+			attrs:       []*pbCommon.KeyValue{stringKV("server.port", "8080")},
+			wantInvalid: []string{"server.port"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing, extra, invalid := Compare(tt.expected, tt.attrs)
+			assert.ElementsMatch(t, tt.wantMissing, missing)
+			assert.ElementsMatch(t, tt.wantExtra, extra)
+			assert.ElementsMatch(t, tt.wantInvalid, invalid)
+		})
+	}
+}
+
+func stringKV(key, value string) *pbCommon.KeyValue {
+	return &pbCommon.KeyValue{
+		Key:   key,
+		Value: &pbCommon.AnyValue{Value: &pbCommon.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+func stringSliceKV(key string, values ...string) *pbCommon.KeyValue {
+	arr := &pbCommon.ArrayValue{}
+	for _, v := range values {
+		arr.Values = append(arr.Values, &pbCommon.AnyValue{Value: &pbCommon.AnyValue_StringValue{StringValue: v}})
+	}
+	return &pbCommon.KeyValue{
+		Key:   key,
+		Value: &pbCommon.AnyValue{Value: &pbCommon.AnyValue_ArrayValue{ArrayValue: arr}},
+	}
+}
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// golden renders the resolved groups, one attribute per line with its level,
+// type and stability.
+func golden(groups map[string]Group) string {
+	ids := make([]string, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	b := strings.Builder{}
+	for _, id := range ids {
+		fmt.Fprintf(&b, "%s\n", id)
+		for _, a := range groups[id].Attributes {
+			fmt.Fprintf(&b, "\t%s %s %s %s\n", a.CanonicalId, a.Level(), a.Type.Name, a.StabilityLevel())
+		}
+	}
+	return b.String()
+}
+
+// TestParseGolden checks the resolved groups of every embedded version. Run
+// go test ./pkg/semconv -run TestParseGolden -update after changing the
+// parsing or the conventions.
+func TestParseGolden(t *testing.T) {
+	versions, err := ParseSemanticVersion()
+	require.NoError(t, err)
+
+	for _, v := range versions {
+		t.Run(v.Dir, func(t *testing.T) {
+			got := golden(v.Groups)
+			file := filepath.Join("testdata", v.Dir+".golden")
+			if *update {
+				require.NoError(t, os.MkdirAll("testdata", 0o755))
+				require.NoError(t, os.WriteFile(file, []byte(got), 0o644))
+			}
+			want, err := os.ReadFile(file)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), got)
+
+			again, err := ParseGroups(filepath.Join("src", v.Dir))
+			require.NoError(t, err)
+			assert.Equal(t, got, golden(again), "resolving must not depend on map order")
+		})
+	}
+}
@@ -2,20 +2,256 @@
 
 package semconv
 
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
 type Group struct {
-	Id         string
-	Type       string
-	Extends    string
-	Attributes []Attribute
+	Id          string
+	Type        string
+	Brief       string
+	Note        string
+	Stability   string
+	Deprecated  string
+	Extends     string
+	Attributes  []Attribute
+	Constraints []Constraint
+	// SpanKind is the kind of a span group, e.g. server.
+	SpanKind string `yaml:"span_kind"`
+	// Events are the event groups a span group can have.
+	Events []string
+	// Name is the name of an event group.
+	Name string
+	// MetricName is the name of a metric group, e.g. http.server.duration.
+	MetricName string `yaml:"metric_name"`
+	// Instrument is the instrument of a metric group, e.g. histogram.
+	Instrument string
+	Unit       string
 
 	Prefix string
 }
 
+// Constraint is a rule on the attributes of a group.
+type Constraint struct {
+	// AnyOf are sets of attributes, all of one set must be present.
+	AnyOf [][]string `yaml:"any_of"`
+	// Include is a group whose constraints also apply.
+	Include string
+}
+
+// UnmarshalYAML reads any_of entries that are an attribute or a list of
+// attributes.
+func (c *Constraint) UnmarshalYAML(value *yaml.Node) error {
+	var raw struct {
+		AnyOf   []yaml.Node `yaml:"any_of"`
+		Include string
+	}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	c.Include = raw.Include
+	for _, n := range raw.AnyOf {
+		if n.Kind == yaml.ScalarNode {
+			c.AnyOf = append(c.AnyOf, []string{n.Value})
+			continue
+		}
+		var set []string
+		if err := n.Decode(&set); err != nil {
+			return err
+		}
+		c.AnyOf = append(c.AnyOf, set)
+	}
+	return nil
+}
+
 type Attribute struct {
-	Id  string
-	Ref string
-	// Type string
+	Id               string
+	Ref              string
+	Type             AttributeType
+	RequirementLevel RequirementLevel `yaml:"requirement_level"`
+	// RequirementNote is the condition of a level, e.g. If applicable.
+	RequirementNote string `yaml:"-"`
+	Brief           string
+	Note            string
+	// Examples is a value or a list of values.
+	Examples         any
+	Stability        string
+	Deprecated       string
+	Tag              string
+	SamplingRelevant bool `yaml:"sampling_relevant"`
 
 	// This is space to hold the prefix.name after parsing.
-	CanonicalId string
+	CanonicalId string `yaml:"-"`
+	// Source is the group that lists the attribute, the group itself or one
+	// it extends.
+	Source string `yaml:"-"`
+	// Definition is the group that defines the attribute.
+	Definition string `yaml:"-"`
+}
+
+// UnmarshalYAML reads the attribute, and the note of its requirement level.
+func (a *Attribute) UnmarshalYAML(value *yaml.Node) error {
+	type plain Attribute
+	if err := value.Decode((*plain)(a)); err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key, level := value.Content[i], value.Content[i+1]
+		if key.Value == "requirement_level" && level.Kind == yaml.MappingNode && len(level.Content) == 2 {
+			a.RequirementNote = level.Content[1].Value
+		}
+	}
+	return nil
+}
+
+// AttributeType is the type of an attribute, e.g. string, int[] or
+// template[string[]]. Enums are represented by their members, and Name holds
+// the type of the member values.
+type AttributeType struct {
+	Name              string
+	AllowCustomValues bool
+	Members           []Member
+}
+
+type Member struct {
+	Id    string
+	Value any
+	Brief string
+	Note  string
+}
+
+func (t *AttributeType) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&t.Name)
+	}
+
+	var enum struct {
+		AllowCustomValues bool `yaml:"allow_custom_values"`
+		Members           []Member
+	}
+	if err := value.Decode(&enum); err != nil {
+		return err
+	}
+	t.AllowCustomValues = enum.AllowCustomValues
+	t.Members = enum.Members
+	if len(t.Members) > 0 {
+		switch t.Members[0].Value.(type) {
+		case int:
+			t.Name = "int"
+		case float64:
+			t.Name = "double"
+		default:
+			t.Name = "string"
+		}
+	}
+	return nil
+}
+
+// IsTemplate reports if the attribute is a template, e.g. http.request.header.<key>.
+// Templates are matched by prefix.
+func (t AttributeType) IsTemplate() bool {
+	return strings.HasPrefix(t.Name, "template[")
+}
+
+// ValueType is the type of the attribute's value. For templates this is the
+// type of each expanded key.
+func (t AttributeType) ValueType() string {
+	if t.IsTemplate() {
+		return strings.TrimSuffix(strings.TrimPrefix(t.Name, "template["), "]")
+	}
+	return t.Name
+}
+
+// RequirementLevel is how strongly the semantic convention asks for an attribute.
+type RequirementLevel string
+
+const (
+	Required              RequirementLevel = "required"
+	ConditionallyRequired RequirementLevel = "conditionally_required"
+	Recommended           RequirementLevel = "recommended"
+	OptIn                 RequirementLevel = "opt_in"
+)
+
+// UnmarshalYAML reads either a level, or a level with a note, e.g.
+// conditionally_required: If applicable.
+func (r *RequirementLevel) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode((*string)(r))
+	}
+	var level map[string]any
+	if err := value.Decode(&level); err != nil {
+		return err
+	}
+	for k := range level {
+		*r = RequirementLevel(k)
+	}
+	return nil
+}
+
+// Level is the requirement level of the attribute. When it isn't set the
+// semantic convention default, recommended, is used.
+func (a Attribute) Level() RequirementLevel {
+	if a.RequirementLevel == "" {
+		return Recommended
+	}
+	return a.RequirementLevel
+}
+
+// CardinalityWarning reports if the note of the attribute warns that it can
+// cause high cardinality, e.g. server.address on HTTP server metrics.
+func (a Attribute) CardinalityWarning() bool {
+	return strings.Contains(strings.ToLower(a.Note), "cardinality")
+}
+
+// Stability levels of attributes and groups.
+const (
+	Stable       = "stable"
+	Experimental = "experimental"
+	Deprecated   = "deprecated"
+)
+
+// StabilityLevel is the stability of the attribute. A deprecated attribute is
+// deprecated, and one without a stability is experimental, as in the
+// semantic conventions.
+func (a Attribute) StabilityLevel() string {
+	switch {
+	case a.Deprecated != "":
+		return Deprecated
+	case a.Stability == "":
+		return Experimental
+	}
+	return a.Stability
+}
+
+// HasStability reports if the version marks attributes stable. Versions
+// before 1.23.0 only mark some deprecated, so all their other attributes are
+// experimental.
+func (v SemanticVersion) HasStability() bool {
+	for _, g := range v.Groups {
+		for _, a := range g.Attributes {
+			if a.StabilityLevel() == Stable {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AttributeStability is the stability of an attribute of the group. A stable
+// attribute of an experimental or deprecated group, like a metric, isn't
+// stable there.
+func (g Group) AttributeStability(a Attribute) string {
+	level := a.StabilityLevel()
+	if level != Stable {
+		return level
+	}
+	switch {
+	case g.Deprecated != "":
+		return Deprecated
+	case g.Stability != "":
+		return g.Stability
+	}
+	return level
 }
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"sort"
+	"strings"
+)
+
+// GroupTypes are the types of groups.
+var GroupTypes = []string{"span", "metric", "resource", "event", "attribute_group"}
+
+// GroupsOfType returns the groups of the type sorted by id, or every group
+// when typ is empty.
+func (v SemanticVersion) GroupsOfType(typ string) []Group {
+	groups := []Group{}
+	for _, g := range v.Groups {
+		if typ == "" || g.Type == typ {
+			groups = append(groups, g)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Id < groups[j].Id })
+	return groups
+}
+
+// SearchResult is a group, or an attribute with the group that defines it,
+// found by Search.
+type SearchResult struct {
+	Group string
+	// Attribute is empty when the group matched.
+	Attribute string
+	Brief     string
+}
+
+// Search finds the groups, then the attributes, whose id or brief contains
+// the keyword, ignoring case.
+func (v SemanticVersion) Search(keyword string) []SearchResult {
+	keyword = strings.ToLower(keyword)
+	contains := func(fields ...string) bool {
+		for _, f := range fields {
+			if strings.Contains(strings.ToLower(f), keyword) {
+				return true
+			}
+		}
+		return false
+	}
+
+	results := []SearchResult{}
+	for _, g := range v.GroupsOfType("") {
+		if contains(g.Id, g.Brief) {
+			results = append(results, SearchResult{Group: g.Id, Brief: g.Brief})
+		}
+	}
+	if v.Registry == nil {
+		return results
+	}
+	for _, id := range v.Registry.Attributes() {
+		a, _ := v.Registry.Attribute(id)
+		if contains(id, a.Brief) {
+			results = append(results, SearchResult{Group: a.Definition, Attribute: id, Brief: a.Brief})
+		}
+	}
+	return results
+}
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupsOfType(t *testing.T) {
+	versions, err := ParseSemanticVersion()
+	require.NoError(t, err)
+	v := versions["https://opentelemetry.io/schemas/1.24.0"]
+
+	spans := v.GroupsOfType("span")
+	require.NotEmpty(t, spans)
+	ids := []string{}
+	for _, g := range spans {
+		assert.Equal(t, "span", g.Type)
+		ids = append(ids, g.Id)
+	}
+	assert.Contains(t, ids, "trace.http.server")
+	assert.IsNonDecreasing(t, ids)
+	assert.Len(t, v.GroupsOfType(""), len(v.Groups))
+	assert.Empty(t, v.GroupsOfType("span_event"))
+}
+
+func TestSearch(t *testing.T) {
+	versions, err := ParseSemanticVersion()
+	require.NoError(t, err)
+	v := versions["https://opentelemetry.io/schemas/1.24.0"]
+
+	results := v.Search("REQUEST.METHOD")
+	require.NotEmpty(t, results)
+	assert.Contains(t, results, SearchResult{
+		Group:     "registry.http",
+		Attribute: "http.request.method",
+		Brief:     "HTTP request method.",
+	})
+	for _, r := range results {
+		assert.NotEmpty(t, r.Attribute, "no group id or brief has the keyword")
+	}
+
+	results = v.Search("http.server")
+	require.NotEmpty(t, results)
+	assert.Equal(t, SearchResult{Group: "attributes.http.server", Brief: results[0].Brief}, results[0], "groups come first")
+}
+
+func TestExplainSources(t *testing.T) {
+	groups, err := ParseGroups("src/v1.24.0")
+	require.NoError(t, err)
+
+	sources := map[string]Attribute{}
+	for _, a := range groups["metric.http.server.request.duration"].Attributes {
+		sources[a.CanonicalId] = a
+	}
+	method := sources["http.request.method"]
+	assert.Equal(t, "attributes.http.common", method.Source, "inherited along the extends chain")
+	assert.Equal(t, "registry.http", method.Definition)
+}
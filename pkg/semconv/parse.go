@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/fs"
 	"path"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -25,6 +26,8 @@ type SemanticVersion struct {
 	Url    string `yaml:"url"`
 	Dir    string `yaml:"dir"`
 	Groups map[string]Group
+	// Registry indexes the attributes and metrics of the groups.
+	Registry *Registry `yaml:"-"`
 }
 
 type File struct {
@@ -46,17 +49,34 @@ func ParseSemanticVersion() (map[string]SemanticVersion, error) {
 	}
 	versions := make(map[string]SemanticVersion)
 	for _, v := range v.Versions {
-		groups, err := ParseGroups(path.Join("src", v.Dir))
+		groups, attributes, err := parseGroups(path.Join("src", v.Dir))
 		if err != nil {
 			return nil, err
 		}
 		v.Groups = groups
+		v.Registry = newRegistry(attributes, groups)
 		versions[v.Url] = v
 	}
 	return versions, nil
 }
 
 func ParseGroups(dir string) (map[string]Group, error) {
+	groups, _, err := parseGroups(dir)
+	return groups, err
+}
+
+// ParseRegistry parses the groups of the directory and indexes them.
+func ParseRegistry(dir string) (*Registry, error) {
+	groups, attributes, err := parseGroups(dir)
+	if err != nil {
+		return nil, err
+	}
+	return newRegistry(attributes, groups), nil
+}
+
+// parseGroups returns the denormalized groups, and the attribute definitions
+// by canonical id.
+func parseGroups(dir string) (map[string]Group, map[string]Attribute, error) {
 	groups := make(map[string]Group)
 	err := fs.WalkDir(files, dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -81,56 +101,144 @@ func ParseGroups(dir string) (map[string]Group, error) {
 		}
 		return nil
 	})
-	return denormalizeGroups(groups), err
+	if err != nil {
+		return nil, nil, err
+	}
+	return denormalizeGroups(groups)
 }
 
-func denormalizeGroups(groups map[string]Group) map[string]Group {
-	attributes := map[string]Attribute{}
+// denormalizeGroups resolves the refs and extends of the groups, so every
+// group has all its attributes with their canonical ids. An attribute of a
+// group overrides the one it inherits with the same id, and is only listed
+// once. It also returns the attribute definitions by canonical id.
+func denormalizeGroups(groups map[string]Group) (map[string]Group, map[string]Attribute, error) {
+	ids := make([]string, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
 
-	// For all non-reference attributes:
-	// - Make their CanonicalId (prefix+id)
-	// - Create a global lookup for that attribute
-	for _, g := range groups {
+	attributes := map[string]Attribute{}
+	for _, id := range ids {
+		g := groups[id]
 		for _, a := range g.Attributes {
 			if a.Id == "" {
 				continue
 			}
-
 			a.CanonicalId = canonicalName(g.Prefix, a.Id)
+			a.Definition = id
+			if _, ok := attributes[a.CanonicalId]; ok {
+				return nil, nil, fmt.Errorf("group %s: duplicate attribute %s", id, a.CanonicalId)
+			}
 			attributes[a.CanonicalId] = a
 		}
 	}
 
-	for _, g := range groups {
-		for i, a := range g.Attributes {
-			if a.Ref == "" {
-				continue
-			}
-			g.Attributes[i] = attributes[a.Ref]
+	r := resolver{groups: groups, attributes: attributes, resolved: map[string]Group{}, visiting: map[string]bool{}}
+	for _, id := range ids {
+		if _, err := r.resolve(id, nil); err != nil {
+			return nil, nil, err
 		}
 	}
+	return r.resolved, attributes, nil
+}
+
+// resolver resolves the groups depth first along extends, each group once.
+type resolver struct {
+	groups     map[string]Group
+	attributes map[string]Attribute
+	resolved   map[string]Group
+	// visiting are the groups being resolved, to find extends cycles.
+	visiting map[string]bool
+}
 
-	for id, g := range groups {
-		for g.Extends != "" {
-			g.Attributes = append(g.Attributes, groups[g.Extends].Attributes...)
-			g.Extends = groups[g.Extends].Extends
+// resolve returns the group with its own attributes first, then the
+// attributes it inherits and doesn't override. chain are the groups that
+// extend it, for the cycle error.
+func (r resolver) resolve(id string, chain []string) (Group, error) {
+	if g, ok := r.resolved[id]; ok {
+		return g, nil
+	}
+	chain = append(chain, id)
+	if r.visiting[id] {
+		return Group{}, fmt.Errorf("extends cycle %s", strings.Join(chain, " -> "))
+	}
+	r.visiting[id] = true
+	defer delete(r.visiting, id)
+
+	g := r.groups[id]
+	var parent Group
+	if g.Extends != "" {
+		if _, ok := r.groups[g.Extends]; !ok {
+			return Group{}, fmt.Errorf("group %s: extends unknown group %s", id, g.Extends)
 		}
-		for i, a := range g.Attributes {
-			if a.Id == "" {
-				g.Attributes = append(g.Attributes, attributes[a.Ref])
-				continue
-			}
-			if a.CanonicalId != "" {
-				continue
-			}
+		var err error
+		if parent, err = r.resolve(g.Extends, chain); err != nil {
+			return Group{}, err
+		}
+	}
+	inherited := map[string]Attribute{}
+	for _, a := range parent.Attributes {
+		inherited[a.CanonicalId] = a
+	}
+
+	own := make([]Attribute, 0, len(g.Attributes)+len(parent.Attributes))
+	seen := map[string]bool{}
+	for _, a := range g.Attributes {
+		if a.Ref == "" {
 			a.CanonicalId = canonicalName(g.Prefix, a.Id)
-			g.Attributes[i] = a
+			a.Definition = id
+		} else {
+			base, ok := inherited[a.Ref]
+			if !ok {
+				if base, ok = r.attributes[a.Ref]; !ok {
+					return Group{}, fmt.Errorf("group %s: unknown attribute ref %s", id, a.Ref)
+				}
+			}
+			a = a.override(base)
+		}
+		a.Source = id
+		if seen[a.CanonicalId] {
+			return Group{}, fmt.Errorf("group %s: duplicate attribute %s", id, a.CanonicalId)
 		}
-		groups[id] = g
+		seen[a.CanonicalId] = true
+		own = append(own, a)
 	}
+	for _, a := range parent.Attributes {
+		if !seen[a.CanonicalId] {
+			own = append(own, a)
+		}
+	}
+	g.Attributes = own
+	r.resolved[id] = g
+	return g, nil
+}
 
-	return groups
+// override returns the referenced attribute with the fields the reference
+// sets, they are specific to the group.
+func (a Attribute) override(ref Attribute) Attribute {
+	if a.RequirementLevel != "" {
+		ref.RequirementLevel = a.RequirementLevel
+		ref.RequirementNote = a.RequirementNote
+	}
+	if a.Brief != "" {
+		ref.Brief = a.Brief
+	}
+	if a.Note != "" {
+		ref.Note = a.Note
+	}
+	if a.Examples != nil {
+		ref.Examples = a.Examples
+	}
+	if a.Tag != "" {
+		ref.Tag = a.Tag
+	}
+	if a.SamplingRelevant {
+		ref.SamplingRelevant = true
+	}
+	return ref
 }
+
 func canonicalName(prefix, name string) string {
 	if prefix != "" {
 		return fmt.Sprintf("%s.%s", prefix, name)
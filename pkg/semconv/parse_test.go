@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseGroups(t *testing.T) {
@@ -52,3 +53,260 @@ func TestParseSemanticVersion(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, versions, 5)
 }
+
+func TestParseTemplateAttributes(t *testing.T) {
+	groups, err := ParseGroups("src/v1.21.0")
+	require.NoError(t, err)
+
+	found := false
+	for _, attr := range groups["trace.http.common"].Attributes {
+		if attr.CanonicalId != "http.request.header" {
+			continue
+		}
+		found = true
+		assert.True(t, attr.Type.IsTemplate())
+		assert.Equal(t, "string[]", attr.Type.ValueType())
+	}
+	assert.True(t, found, "http.request.header not found")
+}
+
+func TestParseEnumAttributes(t *testing.T) {
+	groups, err := ParseGroups("src/v1.21.0")
+	require.NoError(t, err)
+
+	for _, attr := range groups["attributes.http.common"].Attributes {
+		if attr.CanonicalId != "http.request.method" {
+			continue
+		}
+		assert.Equal(t, "string", attr.Type.Name)
+		assert.True(t, attr.Type.AllowCustomValues)
+		assert.NotEmpty(t, attr.Type.Members)
+		return
+	}
+	t.Fatal("http.request.method not found")
+}
+
+func TestParseRequirementLevel(t *testing.T) {
+	groups, err := ParseGroups("src/v1.21.0")
+	require.NoError(t, err)
+
+	levels := map[string]RequirementLevel{}
+	for _, attr := range groups["trace.http.server"].Attributes {
+		levels[attr.CanonicalId] = attr.Level()
+	}
+	// Overridden by the reference in trace.http.server.
+	assert.Equal(t, Required, levels["url.scheme"])
+	assert.Equal(t, ConditionallyRequired, levels["http.route"])
+	assert.Equal(t, Recommended, levels["server.port"])
+}
+
+func TestParseCardinalityWarning(t *testing.T) {
+	groups, err := ParseGroups("src/v1.24.0")
+	require.NoError(t, err)
+
+	warned := map[string]bool{}
+	for _, attr := range groups["metric_attributes.http.server"].Attributes {
+		if _, ok := warned[attr.CanonicalId]; !ok {
+			warned[attr.CanonicalId] = attr.CardinalityWarning()
+		}
+	}
+	// The note of the reference in metric_attributes.http.server warns, the
+	// extended attributes.http.server doesn't.
+	assert.True(t, warned["server.address"])
+	assert.True(t, warned["server.port"])
+	assert.False(t, warned["url.scheme"])
+}
+
+func TestParseMetadata(t *testing.T) {
+	versions, err := ParseSemanticVersion()
+	require.NoError(t, err)
+	require.Len(t, versions, 5)
+
+	for url, v := range versions {
+		t.Run(url, func(t *testing.T) {
+			server := v.Groups["trace.http.server"]
+			assert.NotEmpty(t, server.Brief)
+			assert.Equal(t, "server", server.SpanKind)
+			route := Attribute{}
+			for _, a := range server.Attributes {
+				if a.CanonicalId == "http.route" && a.RequirementLevel != "" {
+					route = a
+				}
+			}
+			assert.Equal(t, ConditionallyRequired, route.RequirementLevel)
+			assert.Equal(t, "If and only if it's available", route.RequirementNote)
+			assert.NotEmpty(t, route.Examples)
+
+			assert.Equal(t, []string{"rpc.message"}, v.Groups["rpc"].Events)
+			assert.Equal(t, "event", v.Groups["rpc.message"].Type)
+
+			assert.Contains(t, v.Groups["process"].Constraints[0].AnyOf, []string{"process.executable.name"})
+			assert.Contains(t, v.Groups["db.tech"].Constraints, Constraint{Include: "db.sql"})
+
+			metrics := 0
+			for _, g := range v.Groups {
+				if g.MetricName == "" {
+					continue
+				}
+				metrics++
+				assert.NotEmpty(t, g.Instrument, g.Id)
+				assert.NotEmpty(t, g.Unit, g.Id)
+			}
+			assert.NotZero(t, metrics)
+
+			var tagged, sampling, memberBriefs bool
+			for _, g := range v.Groups {
+				for _, a := range g.Attributes {
+					tagged = tagged || a.Tag != ""
+					sampling = sampling || a.SamplingRelevant
+					for _, m := range a.Type.Members {
+						memberBriefs = memberBriefs || m.Brief != ""
+					}
+				}
+			}
+			assert.True(t, tagged, "tag")
+			assert.True(t, sampling, "sampling_relevant")
+			assert.True(t, memberBriefs, "member brief")
+		})
+	}
+}
+
+func TestParseEventName(t *testing.T) {
+	groups, err := ParseGroups("src/v1.24.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, "device.app.lifecycle", groups["ios.lifecycle.events"].Name)
+}
+
+func TestParseReferenceOverrides(t *testing.T) {
+	groups, err := ParseGroups("src/v1.24.0")
+	require.NoError(t, err)
+
+	// metric_attributes.http.server references server.address with its own
+	// level and note, the registry definition has neither.
+	found := false
+	for _, a := range groups["metric_attributes.http.server"].Attributes {
+		if a.CanonicalId != "server.address" {
+			continue
+		}
+		found = true
+		assert.Equal(t, OptIn, a.RequirementLevel)
+		assert.Contains(t, a.Note, "cardinality")
+		assert.NotEmpty(t, a.Brief, "kept from the definition")
+		break
+	}
+	assert.True(t, found)
+
+	r, err := ParseRegistry("src/v1.24.0")
+	require.NoError(t, err)
+	def, ok := r.Attribute("server.address")
+	require.True(t, ok)
+	assert.Empty(t, def.RequirementLevel)
+	assert.NotContains(t, def.Note, "cardinality")
+}
+
+func TestParseStability(t *testing.T) {
+	r, err := ParseRegistry("src/v1.24.0")
+	require.NoError(t, err)
+
+	for name, want := range map[string]string{
+		"http.request.method":    Stable,
+		"http.request.body.size": Experimental,
+		"http.method":            Deprecated,
+		"db.system":              Experimental,
+	} {
+		a, ok := r.Attribute(name)
+		require.True(t, ok, name)
+		assert.Equal(t, want, a.StabilityLevel(), name)
+	}
+
+	method, _ := r.Attribute("http.request.method")
+	assert.Equal(t, Stable, Group{Stability: Stable}.AttributeStability(method.Attribute))
+	assert.Equal(t, Stable, Group{}.AttributeStability(method.Attribute))
+	assert.Equal(t, Experimental, Group{Stability: Experimental}.AttributeStability(method.Attribute))
+	assert.Equal(t, Deprecated, Group{Deprecated: "Removed."}.AttributeStability(method.Attribute))
+
+	svs, err := ParseSemanticVersion()
+	require.NoError(t, err)
+	assert.True(t, svs["https://opentelemetry.io/schemas/1.24.0"].HasStability())
+	assert.False(t, svs[DefaultVersion].HasStability(), "1.21.0 marks no attribute stable")
+}
+
+func TestDenormalizeGroups(t *testing.T) {
+	groups, _, err := denormalizeGroups(map[string]Group{
+		"registry.x": {Id: "registry.x", Prefix: "x", Attributes: []Attribute{
+			{Id: "one", Type: AttributeType{Name: "string"}, Brief: "One."},
+			{Id: "two", Type: AttributeType{Name: "int"}},
+		}},
+		"base": {Id: "base", Attributes: []Attribute{
+			{Ref: "x.one", RequirementLevel: Required},
+			{Ref: "x.two"},
+		}},
+		"middle": {Id: "middle", Extends: "base", Attributes: []Attribute{
+			{Ref: "x.two", RequirementLevel: OptIn},
+		}},
+		"leaf": {Id: "leaf", Extends: "middle", Attributes: []Attribute{
+			{Ref: "x.one", Note: "Leaf note."},
+			{Id: "three", Type: AttributeType{Name: "double"}},
+		}},
+	})
+	require.NoError(t, err)
+
+	leaf := groups["leaf"]
+	ids := []string{}
+	for _, a := range leaf.Attributes {
+		ids = append(ids, a.CanonicalId)
+	}
+	assert.Equal(t, []string{"x.one", "three", "x.two"}, ids, "own attributes first, each once")
+	assert.Equal(t, Required, leaf.Attributes[0].RequirementLevel, "inherited from base")
+	assert.Equal(t, "Leaf note.", leaf.Attributes[0].Note)
+	assert.Equal(t, "One.", leaf.Attributes[0].Brief, "from the definition")
+	assert.Equal(t, OptIn, leaf.Attributes[2].RequirementLevel, "middle overrides base")
+	assert.Equal(t, "middle", leaf.Extends)
+}
+
+func TestDenormalizeGroupsErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		groups  map[string]Group
+		wantErr string
+	}{
+		"cycle": {
+			groups: map[string]Group{
+				"a": {Id: "a", Extends: "b"},
+				"b": {Id: "b", Extends: "c"},
+				"c": {Id: "c", Extends: "a"},
+			},
+			wantErr: "extends cycle a -> b -> c -> a",
+		},
+		"self": {
+			groups:  map[string]Group{"a": {Id: "a", Extends: "a"}},
+			wantErr: "extends cycle a -> a",
+		},
+		"missing extends": {
+			groups:  map[string]Group{"a": {Id: "a", Extends: "b"}},
+			wantErr: "group a: extends unknown group b",
+		},
+		"dangling ref": {
+			groups:  map[string]Group{"a": {Id: "a", Attributes: []Attribute{{Ref: "x.one"}}}},
+			wantErr: "group a: unknown attribute ref x.one",
+		},
+		"duplicate definition": {
+			groups: map[string]Group{
+				"a": {Id: "a", Prefix: "x", Attributes: []Attribute{{Id: "one"}}},
+				"b": {Id: "b", Attributes: []Attribute{{Id: "x.one"}}},
+			},
+			wantErr: "group b: duplicate attribute x.one",
+		},
+		"duplicate ref": {
+			groups: map[string]Group{
+				"a": {Id: "a", Prefix: "x", Attributes: []Attribute{{Id: "one"}, {Ref: "x.one"}}},
+			},
+			wantErr: "group a: duplicate attribute x.one",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := denormalizeGroups(tt.groups)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
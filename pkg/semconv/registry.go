@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"sort"
+	"strings"
+)
+
+// Registry indexes the attributes and metrics of a semantic version.
+type Registry struct {
+	attributes map[string]RegistryAttribute
+	templates  []string
+	metrics    map[string]Group
+}
+
+// RegistryAttribute is the definition of an attribute, with the groups that
+// define or reference it.
+type RegistryAttribute struct {
+	Attribute
+	Groups []string
+}
+
+// newRegistry indexes the attribute definitions, and the denormalized groups
+// for the references and metrics.
+func newRegistry(definitions map[string]Attribute, groups map[string]Group) *Registry {
+	r := &Registry{
+		attributes: map[string]RegistryAttribute{},
+		metrics:    map[string]Group{},
+	}
+	for id, a := range definitions {
+		r.attributes[id] = RegistryAttribute{Attribute: a}
+		if a.Type.IsTemplate() {
+			r.templates = append(r.templates, id)
+		}
+	}
+	sort.Strings(r.templates)
+
+	ids := make([]string, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		g := groups[id]
+		if g.MetricName != "" {
+			r.metrics[g.MetricName] = g
+		}
+		for _, a := range g.Attributes {
+			ra, ok := r.attributes[a.CanonicalId]
+			if !ok || (len(ra.Groups) > 0 && ra.Groups[len(ra.Groups)-1] == id) {
+				continue
+			}
+			ra.Groups = append(ra.Groups, id)
+			r.attributes[a.CanonicalId] = ra
+		}
+	}
+	return r
+}
+
+// Attribute returns the attribute with the canonical id, e.g.
+// http.request.method.
+func (r *Registry) Attribute(id string) (RegistryAttribute, bool) {
+	a, ok := r.attributes[id]
+	return a, ok
+}
+
+// Resolve returns the attribute of a key, the key can be an expanded
+// template, e.g. http.request.header.content-type.
+func (r *Registry) Resolve(key string) (RegistryAttribute, bool) {
+	if a, ok := r.attributes[key]; ok {
+		return a, true
+	}
+	for _, t := range r.templates {
+		if strings.HasPrefix(key, t+".") {
+			return r.attributes[t], true
+		}
+	}
+	return RegistryAttribute{}, false
+}
+
+// Metric returns the group of the metric, e.g. http.server.duration.
+func (r *Registry) Metric(name string) (Group, bool) {
+	g, ok := r.metrics[name]
+	return g, ok
+}
+
+// Attributes returns the canonical ids of the attributes, sorted.
+func (r *Registry) Attributes() []string {
+	ids := make([]string, 0, len(r.attributes))
+	for id := range r.attributes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Metrics returns the metric names, sorted.
+func (r *Registry) Metrics() []string {
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
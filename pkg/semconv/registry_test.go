@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package semconv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	r, err := ParseRegistry("src/v1.21.0")
+	require.NoError(t, err)
+
+	method, ok := r.Attribute("http.request.method")
+	require.True(t, ok)
+	assert.Equal(t, "HTTP request method.", method.Brief)
+	assert.Equal(t, []any{"GET", "POST", "HEAD"}, method.Examples)
+	assert.NotEmpty(t, method.Type.Members)
+	assert.Equal(t, Required, method.Level(), "the definition, not a reference")
+	assert.Contains(t, method.Groups, "attributes.http.common", "the group defining it")
+	assert.Contains(t, method.Groups, "db.elasticsearch", "a group referencing it")
+
+	_, ok = r.Attribute("http.request.methd")
+	assert.False(t, ok)
+
+	header, ok := r.Resolve("http.request.header.content-type")
+	require.True(t, ok)
+	assert.Equal(t, "http.request.header", header.CanonicalId)
+
+	duration, ok := r.Metric("http.server.request.duration")
+	require.True(t, ok)
+	assert.Equal(t, "metric.http.server.request.duration", duration.Id)
+
+	assert.Contains(t, r.Attributes(), "url.scheme")
+	assert.Contains(t, r.Metrics(), "http.client.request.duration")
+}
+
+func TestSemanticVersionRegistry(t *testing.T) {
+	versions, err := ParseSemanticVersion()
+	require.NoError(t, err)
+
+	for url, v := range versions {
+		require.NotNil(t, v.Registry, url)
+		_, ok := v.Registry.Attribute("service.name")
+		assert.True(t, ok, url)
+	}
+}
@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+// assertionGroup is the group of value findings for attributes that aren't
+// from a group.
+const assertionGroup = "assertions"
+
+// Assertion checks the value of an attribute, e.g. that url.scheme is http or
+// https. Every field that is set must hold.
+type Assertion struct {
+	Name string
+	// Pattern is a regular expression the value must match.
+	Pattern string
+	// Values are the allowed values.
+	Values []string
+	// Min and Max are the inclusive range of a numeric value.
+	Min *float64
+	Max *float64
+}
+
+type assertion struct {
+	name    string
+	pattern *regexp.Regexp
+	values  map[string]bool
+	min     *float64
+	max     *float64
+}
+
+func newAssertions(section string, raw []Assertion) ([]assertion, []error) {
+	asserts := []assertion{}
+	errs := []error{}
+	for i, a := range raw {
+		sec := fmt.Sprintf("%s: assertions[%d]", section, i)
+		if a.Name == "" {
+			errs = append(errs, fmt.Errorf("%s: empty name", sec))
+		}
+		if a.Pattern == "" && len(a.Values) == 0 && a.Min == nil && a.Max == nil {
+			errs = append(errs, fmt.Errorf("%s: pattern, values, min or max is required", sec))
+		}
+		if a.Min != nil && a.Max != nil && *a.Min > *a.Max {
+			errs = append(errs, fmt.Errorf("%s: min %v is greater than max %v", sec, *a.Min, *a.Max))
+		}
+		as := assertion{name: a.Name, min: a.Min, max: a.Max}
+		if a.Pattern != "" {
+			reg, err := regexp.Compile(a.Pattern)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid pattern %q: %w", sec, a.Pattern, err))
+			}
+			as.pattern = reg
+		}
+		if len(a.Values) > 0 {
+			as.values = map[string]bool{}
+			for _, v := range a.Values {
+				as.values[v] = true
+			}
+		}
+		asserts = append(asserts, as)
+	}
+	return asserts, errs
+}
+
+// holds reports if the value passes the assertion. Every element of an array
+// must pass.
+func (a assertion) holds(value *v1.AnyValue) bool {
+	if arr, ok := value.GetValue().(*v1.AnyValue_ArrayValue); ok {
+		for _, v := range arr.ArrayValue.GetValues() {
+			if !a.holds(v) {
+				return false
+			}
+		}
+		return true
+	}
+	s, ok := valueString(value)
+	if !ok {
+		return false
+	}
+	if a.pattern != nil && !a.pattern.MatchString(s) {
+		return false
+	}
+	if a.values != nil && !a.values[s] {
+		return false
+	}
+	if a.min != nil || a.max != nil {
+		n, ok := numericValue(value)
+		if !ok || (a.min != nil && n < *a.min) || (a.max != nil && n > *a.max) {
+			return false
+		}
+	}
+	return true
+}
+
+// valueString formats a scalar value the way it is written in the config, so
+// an int 200 matches "200" and a bool matches "true".
+func valueString(value *v1.AnyValue) (string, bool) {
+	switch v := value.GetValue().(type) {
+	case *v1.AnyValue_StringValue:
+		return v.StringValue, true
+	case *v1.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10), true
+	case *v1.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64), true
+	case *v1.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue), true
+	}
+	return "", false
+}
+
+// numericValue is the value of an int or double. Strings aren't numbers, a
+// status code sent as "200" has the wrong type.
+func numericValue(value *v1.AnyValue) (float64, bool) {
+	switch v := value.GetValue().(type) {
+	case *v1.AnyValue_IntValue:
+		return float64(v.IntValue), true
+	case *v1.AnyValue_DoubleValue:
+		return v.DoubleValue, !math.IsNaN(v.DoubleValue)
+	}
+	return 0, false
+}
+
+// checkAssertions returns the attributes with a value that fails its
+// assertion. Missing attributes aren't checked, they are reported as missing
+// by their group.
+func (m matchDef) checkAssertions(attrs ...[]*v1.KeyValue) []string {
+	failed := []string{}
+	for _, a := range m.assertions {
+		value := findValue(a.name, attrs...)
+		if value != nil && !a.holds(value) {
+			failed = append(failed, a.name)
+		}
+	}
+	return failed
+}
+
+func findValue(name string, attrs ...[]*v1.KeyValue) *v1.AnyValue {
+	for _, aList := range attrs {
+		for _, a := range aList {
+			if a.GetKey() == name {
+				return a.GetValue()
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func intValue(v int64) *v1.AnyValue {
+	return &v1.AnyValue{Value: &v1.AnyValue_IntValue{IntValue: v}}
+}
+
+func stringValue(v string) *v1.AnyValue {
+	return &v1.AnyValue{Value: &v1.AnyValue_StringValue{StringValue: v}}
+}
+
+func TestAssertionHolds(t *testing.T) {
+	asserts, errs := newAssertions("trace[0]", []Assertion{
+		{Name: "url.scheme", Pattern: "^https?$"},
+		{Name: "http.response.status_code", Min: ptr(100.0), Max: ptr(599.0)},
+		{Name: "http.request.method", Values: []string{"GET", "POST"}},
+	})
+	require.Empty(t, errs)
+	scheme, status, method := asserts[0], asserts[1], asserts[2]
+
+	assert.True(t, scheme.holds(stringValue("https")))
+	assert.False(t, scheme.holds(stringValue("ftp")))
+
+	assert.True(t, status.holds(intValue(200)))
+	assert.True(t, status.holds(&v1.AnyValue{Value: &v1.AnyValue_DoubleValue{DoubleValue: 599}}))
+	assert.False(t, status.holds(intValue(600)))
+	assert.False(t, status.holds(stringValue("200")), "a string is not a number")
+
+	assert.True(t, method.holds(stringValue("GET")))
+	assert.False(t, method.holds(stringValue("get")))
+	assert.True(t, method.holds(&v1.AnyValue{Value: &v1.AnyValue_ArrayValue{ArrayValue: &v1.ArrayValue{
+		Values: []*v1.AnyValue{stringValue("GET"), stringValue("POST")},
+	}}}))
+	assert.False(t, method.holds(&v1.AnyValue{Value: &v1.AnyValue_ArrayValue{ArrayValue: &v1.ArrayValue{
+		Values: []*v1.AnyValue{stringValue("GET"), stringValue("PUT")},
+	}}}))
+}
+
+func TestCompareAttributesAssertions(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	m, err := newMatchDef("trace[0]", Match{
+		Include: []string{"url.scheme"},
+		Assertions: []Assertion{
+			{Name: "url.scheme", Pattern: "^https?$"},
+			{Name: "server.port", Min: ptr(1.0), Max: ptr(65535.0)},
+			{Name: "http.route", Pattern: "^/"},
+		},
+	}, nil, defaultPolicy, svs)
+	require.NoError(t, err)
+
+	findings := m.compareAttributes([]*v1.KeyValue{
+		createKeyValue("url.scheme", "ftp"),
+		{Key: "server.port", Value: intValue(70000)},
+	})
+	assert.Equal(t, []Finding{
+		{Group: includeGroup, Attribute: "url.scheme", Category: ValueMismatch},
+		{Group: assertionGroup, Attribute: "server.port", Category: ValueMismatch},
+	}, findings, "a missing http.route isn't a value finding")
+	assert.Len(t, m.policy.failures(findings), 2)
+}
+
+func TestAttrsMatchTypes(t *testing.T) {
+	attrs := []*v1.KeyValue{
+		{Key: "http.response.status_code", Value: intValue(200)},
+		{Key: "error", Value: &v1.AnyValue{Value: &v1.AnyValue_BoolValue{BoolValue: true}}},
+	}
+	assert.True(t, attrsMatch(map[string]string{"http.response.status_code": "200", "error": "true"}, attrs))
+	assert.False(t, attrsMatch(map[string]string{"http.response.status_code": "404"}, attrs))
+}
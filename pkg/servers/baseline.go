@@ -0,0 +1,211 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Baseline is a record of known findings. Findings in the baseline are not
+// reported, so only regressions fail.
+type Baseline struct {
+	Findings []BaselineEntry `yaml:"findings"`
+}
+
+// BaselineEntry is a finding of an item, identified by everything but the
+// category, so an attribute that changes from missing to an incorrect type is
+// still known.
+type BaselineEntry struct {
+	Signal    string   `yaml:"signal" json:"signal"`
+	Service   string   `yaml:"service" json:"service"`
+	Scope     string   `yaml:"scope,omitempty" json:"scope,omitempty"`
+	Name      string   `yaml:"name" json:"name"`
+	Group     string   `yaml:"group,omitempty" json:"group,omitempty"`
+	Attribute string   `yaml:"attribute" json:"attribute"`
+	Category  Category `yaml:"category" json:"category"`
+	// Stability and Advisory are informative, they aren't part of the key.
+	Stability string `yaml:"stability,omitempty" json:"stability,omitempty"`
+	Advisory  bool   `yaml:"advisory,omitempty" json:"advisory,omitempty"`
+}
+
+func (e BaselineEntry) key() baselineKey {
+	return baselineKey{e.itemKey(), e.Group, e.Attribute}
+}
+
+func (e BaselineEntry) itemKey() itemKey {
+	return itemKey{e.Signal, e.Service, e.Scope, e.Name}
+}
+
+type itemKey struct {
+	signal, service, scope, name string
+}
+
+type baselineKey struct {
+	item             itemKey
+	group, attribute string
+}
+
+func newItemKey(item Item) itemKey {
+	return itemKey{item.Signal, item.Service, item.Scope, item.Name}
+}
+
+// ReadBaseline reads a baseline file.
+func ReadBaseline(path string) (Baseline, error) {
+	b := Baseline{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return b, fmt.Errorf("baseline: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &b); err != nil {
+		return b, fmt.Errorf("baseline: %s: %w", path, err)
+	}
+	return b, nil
+}
+
+// Write writes the baseline as YAML.
+func (b Baseline) Write(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(b); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func (b Baseline) sort() {
+	sort.Slice(b.Findings, func(i, j int) bool {
+		a, c := b.Findings[i], b.Findings[j]
+		for _, p := range [][2]string{
+			{a.Signal, c.Signal}, {a.Service, c.Service}, {a.Scope, c.Scope},
+			{a.Name, c.Name}, {a.Group, c.Group}, {a.Attribute, c.Attribute},
+		} {
+			if p[0] != p[1] {
+				return p[0] < p[1]
+			}
+		}
+		return false
+	})
+}
+
+// baseline filters the findings of a server against the entries of its
+// signal, and tracks which entries are fixed. A nil baseline filters nothing.
+type baseline struct {
+	entries map[baselineKey]BaselineEntry
+
+	mu sync.Mutex
+	// checked holds the items that were checked, and found the findings that
+	// are still present.
+	checked map[itemKey]bool
+	found   map[baselineKey]bool
+}
+
+func newBaseline(b Baseline, signal string) *baseline {
+	bl := &baseline{
+		entries: map[baselineKey]BaselineEntry{},
+		checked: map[itemKey]bool{},
+		found:   map[baselineKey]bool{},
+	}
+	for _, e := range b.Findings {
+		if e.Signal == signal {
+			bl.entries[e.key()] = e
+		}
+	}
+	return bl
+}
+
+// filter removes the findings in the baseline.
+func (b *baseline) filter(item Item, findings []Finding) []Finding {
+	if b == nil {
+		return findings
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := newItemKey(item)
+	b.checked[key] = true
+	kept := []Finding{}
+	for _, f := range findings {
+		k := baselineKey{key, f.Group, f.Attribute}
+		if _, ok := b.entries[k]; ok {
+			b.found[k] = true
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// fixed are the entries whose item was checked without the finding.
+func (b *baseline) fixed() []BaselineEntry {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	fixed := []BaselineEntry{}
+	for k, e := range b.entries {
+		if b.checked[k.item] && !b.found[k] {
+			fixed = append(fixed, e)
+		}
+	}
+	return fixed
+}
+
+// FixedBaseline sorts and joins the fixed baseline entries of the servers.
+func FixedBaseline(fixed ...[]BaselineEntry) []BaselineEntry {
+	b := Baseline{Findings: []BaselineEntry{}}
+	for _, f := range fixed {
+		b.Findings = append(b.Findings, f...)
+	}
+	b.sort()
+	return b.Findings
+}
+
+// BaselineRecorder is an Observer that records every finding, to create a
+// baseline.
+type BaselineRecorder struct {
+	mu      sync.Mutex
+	entries map[baselineKey]BaselineEntry
+}
+
+func NewBaselineRecorder() *BaselineRecorder {
+	return &BaselineRecorder{entries: map[baselineKey]BaselineEntry{}}
+}
+
+func (r *BaselineRecorder) Observe(item Item) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, f := range item.Findings {
+		e := BaselineEntry{
+			Signal:    item.Signal,
+			Service:   item.Service,
+			Scope:     item.Scope,
+			Name:      item.Name,
+			Group:     f.Group,
+			Attribute: f.Attribute,
+			Category:  f.Category,
+			Stability: f.Stability,
+			Advisory:  f.Advisory,
+		}
+		r.entries[e.key()] = e
+	}
+}
+
+// Baseline returns the recorded findings, sorted so the file diffs well.
+func (r *BaselineRecorder) Baseline() Baseline {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := Baseline{Findings: make([]BaselineEntry, 0, len(r.entries))}
+	for _, e := range r.entries {
+		b.Findings = append(b.Findings, e)
+	}
+	b.sort()
+	return b
+}
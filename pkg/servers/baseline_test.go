@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaselineReadWrite(t *testing.T) {
+	want := Baseline{Findings: []BaselineEntry{
+		{Signal: "trace", Service: "api", Scope: "otelhttp", Name: "GET /", Group: "trace.http.server", Attribute: "url.scheme", Category: RequiredMissing},
+		{Signal: "trace", Service: "api", Name: "GET /", Attribute: "custom", Category: Extra},
+	}}
+	path := filepath.Join(t.TempDir(), "baseline.yaml")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, want.Write(f))
+	require.NoError(t, f.Close())
+
+	got, err := ReadBaseline(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	_, err = ReadBaseline(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "baseline: ")
+}
+
+func TestBaselineFilter(t *testing.T) {
+	item := Item{Signal: "trace", Service: "api", Name: "GET /"}
+	findings := []Finding{
+		{Group: "trace.http.server", Attribute: "url.scheme", Category: TypeMismatch},
+		{Group: "trace.http.server", Attribute: "http.route", Category: RecommendedMissing},
+	}
+
+	var none *baseline
+	assert.Equal(t, findings, none.filter(item, findings))
+	assert.Nil(t, none.fixed())
+
+	b := newBaseline(Baseline{Findings: []BaselineEntry{
+		{Signal: "trace", Service: "api", Name: "GET /", Group: "trace.http.server", Attribute: "url.scheme", Category: RequiredMissing},
+		{Signal: "trace", Service: "api", Name: "GET /", Group: "trace.http.server", Attribute: "server.port"},
+		{Signal: "log", Service: "api", Name: "GET /", Group: "trace.http.server", Attribute: "http.route"},
+	}}, "trace")
+
+	assert.Equal(t, findings[1:], b.filter(item, findings), "the category isn't part of the key")
+	assert.Equal(t, []BaselineEntry{
+		{Signal: "trace", Service: "api", Name: "GET /", Group: "trace.http.server", Attribute: "server.port"},
+	}, b.fixed())
+}
@@ -0,0 +1,265 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+// cardinalityGroup is the group of high cardinality findings for attributes
+// that aren't from a group.
+const cardinalityGroup = "cardinality"
+
+const defaultCardinalityWindow = 10 * time.Minute
+
+// defaultUnbounded are attributes with a value per request or user, they
+// shouldn't be on metrics whatever their cardinality so far.
+var defaultUnbounded = []string{
+	"url.full",
+	"url.path",
+	"url.query",
+	"http.url",
+	"http.target",
+	"user.id",
+	"enduser.id",
+	"session.id",
+	"db.statement",
+	"exception.message",
+	"exception.stacktrace",
+}
+
+// Cardinality configures the detection of high cardinality attributes on
+// metric data points and span names. Unbounded attributes, and opt in
+// attributes with a cardinality warning, are always flagged on metrics, the
+// rest is disabled unless a limit is set.
+type Cardinality struct {
+	// Limit is the number of distinct values of an attribute of a metric.
+	Limit int
+	// SeriesLimit is the number of distinct attribute sets of a metric.
+	SeriesLimit int `mapstructure:"series_limit"`
+	// SpanNameLimit is the number of distinct span names of a scope.
+	SpanNameLimit int `mapstructure:"span_name_limit"`
+	// Window is how long a value is counted after it was last seen.
+	Window time.Duration
+	// Unbounded are attributes that are flagged on any metric, added to
+	// the defaults like url.full.
+	Unbounded []string
+}
+
+type cardinalityConfig struct {
+	// limited is set when any limit is. Span names are only checked for
+	// high cardinality then.
+	limited       bool
+	limit         int
+	seriesLimit   int
+	spanNameLimit int
+	window        time.Duration
+	unbounded     []pattern
+}
+
+// newCardinalityConfig compiles the config, or returns the errors of an
+// invalid one.
+func newCardinalityConfig(c Cardinality) (cardinalityConfig, []error) {
+	errs := []error{}
+	if c.Limit < 0 {
+		errs = append(errs, fmt.Errorf("cardinality.limit: negative %d", c.Limit))
+	}
+	if c.SeriesLimit < 0 {
+		errs = append(errs, fmt.Errorf("cardinality.series_limit: negative %d", c.SeriesLimit))
+	}
+	if c.SpanNameLimit < 0 {
+		errs = append(errs, fmt.Errorf("cardinality.span_name_limit: negative %d", c.SpanNameLimit))
+	}
+	if c.Window < 0 {
+		errs = append(errs, fmt.Errorf("cardinality.window: negative %s", c.Window))
+	}
+	unbounded, perrs := newPatterns("cardinality.unbounded", append(slices.Clone(defaultUnbounded), c.Unbounded...), nil)
+	errs = append(errs, perrs...)
+	if len(errs) > 0 {
+		return cardinalityConfig{}, errs
+	}
+	window := c.Window
+	if window == 0 {
+		window = defaultCardinalityWindow
+	}
+	return cardinalityConfig{
+		limited:       c.Limit > 0 || c.SeriesLimit > 0 || c.SpanNameLimit > 0,
+		limit:         c.Limit,
+		seriesLimit:   c.SeriesLimit,
+		spanNameLimit: c.SpanNameLimit,
+		window:        window,
+		unbounded:     unbounded,
+	}, nil
+}
+
+// cardinalityTracker counts the distinct values of the data point attributes
+// of each metric, and the span names of each scope. It belongs to the server,
+// so the counts survive a reload.
+type cardinalityTracker struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	metrics   map[metricKey]*metricValues
+	spanNames map[scopeKey]valueWindow
+}
+
+type scopeKey struct {
+	service, scope string
+}
+
+type metricKey struct {
+	service, name string
+}
+
+type metricValues struct {
+	series valueWindow
+	attrs  map[string]valueWindow
+}
+
+func newCardinalityTracker() *cardinalityTracker {
+	return &cardinalityTracker{
+		now:       time.Now,
+		metrics:   map[metricKey]*metricValues{},
+		spanNames: map[scopeKey]valueWindow{},
+	}
+}
+
+// observeSpanName records the span name, and reports if the scope has more
+// names than the limit in the window.
+func (t *cardinalityTracker) observeSpanName(c cardinalityConfig, service, scope, name string) bool {
+	if c.spanNameLimit == 0 {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := scopeKey{service, scope}
+	w := t.spanNames[key]
+	if w == nil {
+		w = valueWindow{}
+		t.spanNames[key] = w
+	}
+	count, _ := w.add(name, t.now(), c.window, c.spanNameLimit+1)
+	return count > c.spanNameLimit
+}
+
+// observe records the attributes of a data point. It returns the attributes
+// that are over the limit or unbounded, and if it is a new series over the
+// series limit.
+func (t *cardinalityTracker) observe(c cardinalityConfig, service, metric string, attrs []*v1.KeyValue) ([]string, bool) {
+	high := []string{}
+	for _, a := range attrs {
+		if slices.ContainsFunc(c.unbounded, func(p pattern) bool { return p.matches(a.GetKey()) }) {
+			high = append(high, a.GetKey())
+		}
+	}
+	if c.limit == 0 && c.seriesLimit == 0 {
+		return high, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	key := metricKey{service, metric}
+	mv := t.metrics[key]
+	if mv == nil {
+		mv = &metricValues{series: valueWindow{}, attrs: map[string]valueWindow{}}
+		t.metrics[key] = mv
+	}
+
+	// The window holds at most one series over the limit, so only that
+	// the limit is exceeded is known.
+	tooMany := false
+	if c.seriesLimit > 0 {
+		count, added := mv.series.add(seriesKey(attrs), now, c.window, c.seriesLimit+1)
+		tooMany = added && count > c.seriesLimit
+	}
+	if c.limit > 0 {
+		for _, a := range attrs {
+			w := mv.attrs[a.GetKey()]
+			if w == nil {
+				w = valueWindow{}
+				mv.attrs[a.GetKey()] = w
+			}
+			count, _ := w.add(attributeValue(a.GetValue()), now, c.window, c.limit+1)
+			if count > c.limit && !slices.Contains(high, a.GetKey()) {
+				high = append(high, a.GetKey())
+			}
+		}
+	}
+	sort.Strings(high)
+	return high, tooMany
+}
+
+// valueWindow is when each distinct value was last seen. It holds at most
+// limit values, so an unbounded attribute doesn't use unbounded memory.
+type valueWindow map[string]time.Time
+
+// add records the value and returns the number of values seen in the window,
+// and if the value is new.
+func (w valueWindow) add(value string, now time.Time, window time.Duration, limit int) (int, bool) {
+	for v, seen := range w {
+		if now.Sub(seen) > window {
+			delete(w, v)
+		}
+	}
+	_, ok := w[value]
+	if !ok && len(w) >= limit {
+		var oldest string
+		var oldestSeen time.Time
+		for v, seen := range w {
+			if oldestSeen.IsZero() || seen.Before(oldestSeen) {
+				oldest, oldestSeen = v, seen
+			}
+		}
+		delete(w, oldest)
+	}
+	w[value] = now
+	return len(w), !ok
+}
+
+// seriesKey identifies the attribute set of a data point, whatever the order
+// of the attributes.
+func seriesKey(attrs []*v1.KeyValue) string {
+	kvs := make([]string, 0, len(attrs))
+	for _, a := range attrs {
+		kvs = append(kvs, a.GetKey()+"="+attributeValue(a.GetValue()))
+	}
+	sort.Strings(kvs)
+	return strings.Join(kvs, "\x00")
+}
+
+func attributeValue(value *v1.AnyValue) string {
+	if s, ok := valueString(value); ok {
+		return s
+	}
+	return value.String()
+}
+
+// cardinalityFindings returns the findings for the high cardinality
+// attributes, and the opt in attributes with a cardinality warning, of a data
+// point.
+func (m matchDef) cardinalityFindings(high []string, attrs []*v1.KeyValue) []Finding {
+	names := slices.Clone(high)
+	for _, a := range attrs {
+		if m.warned[a.GetKey()] && !slices.Contains(names, a.GetKey()) {
+			names = append(names, a.GetKey())
+		}
+	}
+	sort.Strings(names)
+
+	findings := []Finding{}
+	for _, name := range m.filter(names) {
+		group, ok := m.sources[name]
+		if !ok {
+			group = cardinalityGroup
+		}
+		findings = append(findings, m.finding(group, name, HighCardinality))
+	}
+	return findings
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pbCollectorMetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+	pbMetrics "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+func TestCardinalityTracker(t *testing.T) {
+	c, errs := newCardinalityConfig(Cardinality{Limit: 2, SeriesLimit: 3, Window: time.Minute})
+	require.Empty(t, errs)
+
+	now := time.Unix(0, 0)
+	tr := newCardinalityTracker()
+	tr.now = func() time.Time { return now }
+	observe := func(route string, code int64) ([]string, bool) {
+		return tr.observe(c, "api", "http.server.duration", []*v1.KeyValue{
+			createKeyValue("http.route", route),
+			{Key: "http.response.status_code", Value: intValue(code)},
+		})
+	}
+
+	high, tooMany := observe("/a", 200)
+	assert.Empty(t, high)
+	assert.False(t, tooMany)
+	observe("/b", 200)
+	observe("/b", 500)
+	high, tooMany = observe("/c", 200)
+	assert.Equal(t, []string{"http.route"}, high)
+	assert.True(t, tooMany)
+
+	high, tooMany = observe("/c", 200)
+	assert.Equal(t, []string{"http.route"}, high, "still over the limit in the window")
+	assert.False(t, tooMany, "the series isn't new")
+
+	now = now.Add(2 * time.Minute)
+	high, tooMany = observe("/c", 200)
+	assert.Empty(t, high, "the values left the window")
+	assert.False(t, tooMany)
+}
+
+func TestCardinalityTrackerBounded(t *testing.T) {
+	c, errs := newCardinalityConfig(Cardinality{Limit: 10})
+	require.Empty(t, errs)
+
+	tr := newCardinalityTracker()
+	for i := 0; i < 1000; i++ {
+		tr.observe(c, "api", "requests", []*v1.KeyValue{createKeyValue("request.id", fmt.Sprint(i))})
+	}
+	assert.Len(t, tr.metrics[metricKey{"api", "requests"}].attrs["request.id"], 11)
+}
+
+func TestCardinalityWithoutLimits(t *testing.T) {
+	c, errs := newCardinalityConfig(Cardinality{Unbounded: []string{"app.request_id"}})
+	require.Empty(t, errs)
+	require.NotNil(t, c, "unbounded attributes are checked without limits")
+	assert.False(t, c.limited)
+
+	tr := newCardinalityTracker()
+	high, tooMany := tr.observe(c, "api", "requests", []*v1.KeyValue{
+		createKeyValue("app.request_id", "1"),
+		createKeyValue("url.full", "http://localhost/"),
+		createKeyValue("http.route", "/"),
+	})
+	assert.Equal(t, []string{"app.request_id", "url.full"}, high)
+	assert.False(t, tooMany)
+	assert.Empty(t, tr.metrics, "values aren't counted without limits")
+}
+
+func TestMetricsServerCardinality(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	rec := &itemRecorder{}
+	s, err := NewMetricsService(Config{
+		Metrics: []Match{{
+			SemanticVersion: "https://opentelemetry.io/schemas/1.24.0",
+			Groups:          []string{"metric_attributes.http.server"},
+			ErrorPolicy:     ErrorPolicy{Action: string(Accept)},
+		}},
+		Cardinality: Cardinality{Limit: 100, Unbounded: []string{"app.*"}},
+	}, svs, rec)
+	require.NoError(t, err)
+
+	req := &pbCollectorMetrics.ExportMetricsServiceRequest{
+		ResourceMetrics: []*pbMetrics.ResourceMetrics{{
+			ScopeMetrics: []*pbMetrics.ScopeMetrics{{
+				Metrics: []*pbMetrics.Metric{{
+					Name: "http.server.request.duration",
+					Data: &pbMetrics.Metric_Histogram{Histogram: &pbMetrics.Histogram{
+						DataPoints: []*pbMetrics.HistogramDataPoint{{Attributes: []*v1.KeyValue{
+							createKeyValue("url.full", "http://localhost/a"),
+							createKeyValue("server.address", "localhost"),
+							createKeyValue("app.tenant", "a"),
+						}}},
+					}},
+				}},
+			}},
+		}},
+	}
+	_, err = s.Export(context.Background(), req)
+	require.NoError(t, err)
+
+	require.Len(t, rec.items, 1)
+	high := []Finding{}
+	for _, f := range rec.items[0].Findings {
+		if f.Category == HighCardinality {
+			high = append(high, f)
+		}
+	}
+	assert.Equal(t, []Finding{
+		{Group: cardinalityGroup, Attribute: "app.tenant", Category: HighCardinality},
+		{Group: "metric_attributes.http.server", Attribute: "server.address", Category: HighCardinality, Stability: semconv.Stable},
+		{Group: cardinalityGroup, Attribute: "url.full", Category: HighCardinality},
+	}, high)
+}
+
+func TestCardinalityTrackerSpanNames(t *testing.T) {
+	c, errs := newCardinalityConfig(Cardinality{SpanNameLimit: 2})
+	require.Empty(t, errs)
+
+	tr := newCardinalityTracker()
+	assert.False(t, tr.observeSpanName(c, "api", "otelhttp", "GET /a"))
+	assert.False(t, tr.observeSpanName(c, "api", "otelhttp", "GET /b"))
+	assert.False(t, tr.observeSpanName(c, "api", "otelsql", "SELECT"), "scopes are counted apart")
+	assert.True(t, tr.observeSpanName(c, "api", "otelhttp", "GET /c"))
+}
@@ -2,24 +2,127 @@
 
 package servers
 
+import "time"
+
 type Config struct {
-	ServerAddress   string `mapstructure:"server_address"`
+	ServerAddress string `mapstructure:"server_address"`
+	// HTTPAddress serves Prometheus metrics on /metrics, disabled if empty.
+	HTTPAddress     string `mapstructure:"http_address"`
 	Resource        Match
 	Trace           []Match
 	Metrics         []Match
 	Log             []Match
-	ReportUnmatched bool `mapstructure:"report_unmatched"`
-	DisableError    bool `mapstructure:"disable_error"`
+	Ignore          []string
+	ReportUnmatched bool          `mapstructure:"report_unmatched"`
+	DisableError    bool          `mapstructure:"disable_error"`
+	ErrorPolicy     ErrorPolicies `mapstructure:"error_policy"`
+	SelfTelemetry   SelfTelemetry `mapstructure:"self_telemetry"`
+	// Baseline is a file of known findings that aren't reported.
+	Baseline string
+	// Services override the config for the resources they select.
+	Services []Service
+	// Profiles are variants of the config selected per request with the
+	// x-semconv-profile header.
+	Profiles map[string]Profile
+	// Expectations are telemetry services must send.
+	Expectations []Expectation
+	// ExpectationTimeout is how long after starting unmet expectations are
+	// reported, disabled if zero.
+	ExpectationTimeout time.Duration `mapstructure:"expectation_timeout"`
+	// Cardinality flags high cardinality attributes on metrics.
+	Cardinality Cardinality
+}
+
+// Expectation is telemetry a service must send, at least once.
+type Expectation struct {
+	Service string
+	// Trace are groups, e.g. trace.http.server, a span must be matched with.
+	Trace []string
+	// Metrics are metric names, e.g. http.server.request.duration.
+	Metrics []string
+	// Log are groups a log record must be matched with.
+	Log []string
+}
+
+// Profile overrides the config for the requests that select it.
+type Profile struct {
+	// SemanticVersion is used by the matches that don't set one.
+	SemanticVersion string `mapstructure:"semantic_version"`
+	// Ignore is added to the global ignore.
+	Ignore []string
+	// Trace, Metrics and Log replace the global matches when set.
+	Trace       []Match
+	Metrics     []Match
+	Log         []Match
+	ErrorPolicy ErrorPolicies `mapstructure:"error_policy"`
+}
+
+// Service scopes matches, ignore entries and a semantic version to the
+// resources of a service. The first service that selects a resource is used.
+type Service struct {
+	// Name selects resources by service.name.
+	Name string
+	// ResourceAttributes selects resources by attribute, an empty value
+	// matches any value.
+	ResourceAttributes []Attribute `mapstructure:"resource_attributes"`
+	// SemanticVersion is used by the matches that don't set one, including
+	// the global matches.
+	SemanticVersion string `mapstructure:"semantic_version"`
+	// Ignore is added to the global ignore.
+	Ignore []string
+	// Trace, Metrics and Log are added to the global matches.
+	Trace   []Match
+	Metrics []Match
+	Log     []Match
+}
+
+// SelfTelemetry exports the findings and compliance metrics of the checker to
+// an OTLP gRPC endpoint.
+type SelfTelemetry struct {
+	// Endpoint is the host:port to export to, disabled if empty.
+	Endpoint string
+	Insecure bool
+	// Interval between exports, 10s if unset.
+	Interval time.Duration
+}
+
+// ErrorPolicies is the error policy for every signal, each signal can
+// override it.
+type ErrorPolicies struct {
+	ErrorPolicy `mapstructure:",squash"`
+	Trace       ErrorPolicy
+	Metrics     ErrorPolicy
+	Log         ErrorPolicy
+}
+
+// ErrorPolicy is how the server responds to telemetry that doesn't follow the
+// semantic conventions. Unset fields are inherited.
+type ErrorPolicy struct {
+	// Action is one of reject, partial_success or accept.
+	Action string
+	// FailOn are the finding categories that trigger the action, e.g.
+	// required-missing or type-mismatch. missing includes all missing categories.
+	FailOn []string `mapstructure:"fail_on"`
 }
 
 type Match struct {
-	SemanticVersion  string `mapstructure:"semantic_version"`
-	Match            string
-	MatchAttributes  []Attribute `mapstructure:"match_attributes"`
-	Groups           []string
-	Ignore           []string
-	Include          []string
-	ReportAdditional bool `mapstructure:"report_additional"`
+	SemanticVersion string `mapstructure:"semantic_version"`
+	Match           string
+	MatchAttributes []Attribute `mapstructure:"match_attributes"`
+	Groups          []string
+	Ignore          []string
+	Include         []string
+	Assertions      []Assertion
+	// SpanName are templates of the span name, e.g. "{http.request.method}
+	// {http.route}", used instead of the convention of the groups.
+	SpanName         []string `mapstructure:"span_name"`
+	ReportAdditional bool     `mapstructure:"report_additional"`
+	// Lint checks the naming of every attribute key against the registry.
+	Lint bool
+	// Experimental is how attributes that aren't stable are checked: check,
+	// the default, warn or exclude.
+	Experimental string
+	ErrorPolicy  ErrorPolicy `mapstructure:"error_policy"`
 }
 
 type Attribute struct {
@@ -34,15 +137,11 @@ resource:
   groups:
   - host
   - os
-  ignore:
-  -
   report_additional: true
 trace:
 - match: http.server.*
   groups:
   - trace.http.server
-  ignore:
-  -
   report_additional: true
 metric:
 log:
@@ -5,6 +5,7 @@ package servers
 import (
 	"testing"
 
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
 	"github.com/mitchellh/mapstructure"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,8 +27,14 @@ func TestDefaultConfig(t *testing.T) {
 
 	assert.Contains(t, cfg.Resource.MatchAttributes, Attribute{Name: "service.name"})
 
-	assert.NotPanics(t, func() {
-		NewTraceService(cfg, nil)
-		NewMetricsService(cfg, nil)
-	})
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+	assert.NoError(t, cfg.Validate(svs))
+
+	_, err = NewTraceService(cfg, svs)
+	assert.NoError(t, err)
+	_, err = NewMetricsService(cfg, svs)
+	assert.NoError(t, err)
+	_, err = NewLogService(cfg, svs)
+	assert.NoError(t, err)
 }
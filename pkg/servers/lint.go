@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+// lintGroup is the group of the lint findings, they aren't from a group.
+const lintGroup = "lint"
+
+// suggestionLimit bounds the suggestion cache, the keys are from telemetry.
+const suggestionLimit = 1000
+
+// validName is the attribute naming rule: lowercase namespaces separated by
+// dots.
+var validName = regexp.MustCompile(`^[a-z0-9_]+(\.[a-z0-9_]+)*$`)
+
+// registry lints the keys of received attributes against the attributes of
+// a semantic version.
+type registry struct {
+	*semconv.Registry
+	names      []string
+	namespaces map[string]bool
+
+	mu          sync.Mutex
+	suggestions map[string]string
+}
+
+func newRegistry(r *semconv.Registry) *registry {
+	reg := &registry{
+		Registry:    r,
+		names:       r.Attributes(),
+		namespaces:  map[string]bool{},
+		suggestions: map[string]string{},
+	}
+	for _, name := range reg.names {
+		if ns, _, ok := strings.Cut(name, "."); ok {
+			reg.namespaces[ns] = true
+		}
+	}
+	return reg
+}
+
+// suggest finds the registry attribute the key is likely a typo of.
+func (r *registry) suggest(key string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.suggestions[key]; ok {
+		return s
+	}
+	s := suggest(strings.ToLower(key), r.names)
+	if len(r.suggestions) < suggestionLimit {
+		r.suggestions[key] = s
+	}
+	return s
+}
+
+// lint returns the findings for the keys that break the naming rules, e.g.
+// uppercase or not namespaced, and the unknown keys in an OTel namespace.
+// Known keys, and the keys the match expects, aren't linted.
+func (m matchDef) lint(attrs ...[]*v1.KeyValue) []Finding {
+	findings := []Finding{}
+	if m.registry == nil {
+		return findings
+	}
+	seen := map[string]bool{}
+	for _, aList := range attrs {
+		for _, a := range aList {
+			key := a.GetKey()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if _, ok := m.levels[key]; ok {
+				continue
+			}
+			if _, ok := m.registry.Resolve(key); ok {
+				continue
+			}
+			if len(m.filter([]string{key})) == 0 {
+				continue
+			}
+			ns, _, dotted := strings.Cut(key, ".")
+			switch {
+			case !validName.MatchString(key) || !dotted:
+				findings = append(findings, Finding{Group: lintGroup, Attribute: key, Category: Naming, Suggestion: m.registry.suggest(key)})
+			case m.registry.namespaces[ns]:
+				findings = append(findings, Finding{Group: lintGroup, Attribute: key, Category: UnknownAttribute, Suggestion: m.registry.suggest(key)})
+			}
+		}
+	}
+	return findings
+}
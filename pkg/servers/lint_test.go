@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+func TestLint(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	m, err := newMatchDef("trace[0]", Match{
+		Include: []string{"custom"},
+		Ignore:  []string{"legacy.*"},
+		Lint:    true,
+	}, nil, defaultPolicy, svs)
+	require.NoError(t, err)
+
+	findings := m.lint([]*v1.KeyValue{
+		createKeyValue("http.request.method", "GET"),
+		createKeyValue("http.request.header.x-request-id", "1"),
+		createKeyValue("http.request.methd", "GET"),
+		createKeyValue("http.request.method", "GET"),
+		createKeyValue("HTTP.Route", "/"),
+		createKeyValue("tenant", "a"),
+		createKeyValue("app.tenant id", "a"),
+		createKeyValue("app.tenant_id", "a"),
+		createKeyValue("custom", "a"),
+		createKeyValue("legacy.ID", "a"),
+	}, []*v1.KeyValue{
+		createKeyValue("http.request.methd", "GET"),
+	})
+	assert.Equal(t, []Finding{
+		{Group: lintGroup, Attribute: "http.request.methd", Category: UnknownAttribute, Suggestion: "http.request.method"},
+		{Group: lintGroup, Attribute: "HTTP.Route", Category: Naming, Suggestion: "http.route"},
+		{Group: lintGroup, Attribute: "tenant", Category: Naming},
+		{Group: lintGroup, Attribute: "app.tenant id", Category: Naming},
+	}, findings)
+}
+
+func TestLintDisabled(t *testing.T) {
+	m := newTestMatchDef(nil, nil)
+	assert.Empty(t, m.lint([]*v1.KeyValue{createKeyValue("Tenant", "a")}))
+}
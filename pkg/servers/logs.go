@@ -4,81 +4,76 @@ package servers
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
 	"log/slog"
+	"sync/atomic"
 
 	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
 	pbCollectorLogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
-
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
 )
 
+// maxLogName bounds the length of the name of a log record.
+const maxLogName = 100
+
 type LogServer struct {
 	pbCollectorLogs.UnimplementedLogsServiceServer
-	resource        matchDef
-	matches         []matchDef
-	reportUnmatched bool
-
-	disableError bool
+	config    atomic.Pointer[signalConfig]
+	observers observers
 }
 
 var _ pbCollectorLogs.LogsServiceServer = &LogServer{}
 
-func NewLogService(cfg Config, svs map[string]semconv.SemanticVersion) *LogServer {
-	_, found := svs[cfg.Resource.SemanticVersion]
-	if cfg.Resource.SemanticVersion != "" && !found {
-		cfg.Resource.SemanticVersion = semconv.DefaultVersion
+func NewLogService(cfg Config, svs map[string]semconv.SemanticVersion, obs ...Observer) (*LogServer, error) {
+	s := &LogServer{observers: obs}
+	if err := s.Update(cfg, svs); err != nil {
+		return nil, err
 	}
+	return s, nil
+}
 
-	resSemVer := cfg.Resource.SemanticVersion
-	if !found {
-		resSemVer = semconv.DefaultVersion
-	}
-	resource := newMatchDef(cfg.Resource, svs[resSemVer].Groups)
-
-	matches := []matchDef{}
-	for _, match := range cfg.Log {
-		groups, ok := svs[match.SemanticVersion]
-		if !ok {
-			match.SemanticVersion = semconv.DefaultVersion
-			groups = svs[match.SemanticVersion]
-		}
-		matches = append(matches, newMatchDef(match, groups.Groups))
+// Update replaces the config of the server. If the new config is invalid the
+// previous config is kept. The hits of ignore entries carry over to the same
+// entries of the new config.
+func (s *LogServer) Update(cfg Config, svs map[string]semconv.SemanticVersion) error {
+	c, err := newSignalConfig(cfg, "log", svs)
+	if err != nil {
+		return err
 	}
+	c.carryIgnoreHits(s.config.Swap(c))
+	return nil
+}
 
-	return &LogServer{
-		resource:        resource,
-		matches:         matches,
-		reportUnmatched: cfg.ReportUnmatched,
-		disableError:    cfg.DisableError,
-	}
+// IgnoreUsage reports how many attributes each ignore entry has matched.
+func (s *LogServer) IgnoreUsage() []IgnoreUsage {
+	return s.config.Load().ignoreUsage()
+}
+
+// FixedBaseline returns the baseline entries that were checked and no longer
+// found.
+func (s *LogServer) FixedBaseline() []BaselineEntry {
+	return s.config.Load().baseline.fixed()
 }
 
 func (s *LogServer) Export(ctx context.Context, req *pbCollectorLogs.ExportLogsServiceRequest) (*pbCollectorLogs.ExportLogsServiceResponse, error) {
 	if req == nil {
 		return nil, nil
 	}
-	count := 0
-	names := []string{}
+	profile, session := requestHeaders(ctx)
+	cfg, err := s.config.Load().forProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	res := newResult("log record")
 	for _, r := range req.ResourceLogs {
 		log := slog.With("type", "log")
 		if schema := r.GetSchemaUrl(); schema != "" {
 			log = log.With("resource.schema", schema)
 		}
-		if attr := r.Resource.GetAttributes(); len(attr) > 0 {
-			name := ""
-			for _, kv := range attr {
-				if kv.Key == "service.name" {
-					name = kv.Value.GetStringValue()
-				}
-				if name == "" {
-					name = kv.String()
-				}
-			}
-			if name != "" {
-				log = log.With("service.name", name)
-			}
+		service := serviceName(r.GetResource().GetAttributes())
+		matches := cfg.matchesFor(r.GetResource().GetAttributes())
+		if service != "" {
+			log = log.With("service.name", service)
 		}
 
 		for _, scope := range r.ScopeLogs {
@@ -97,39 +92,103 @@ func (s *LogServer) Export(ctx context.Context, req *pbCollectorLogs.ExportLogsS
 			}
 
 			for _, record := range scope.LogRecords {
-				found := false
-				name := record.GetBody().String()
-				if len(name) > 100 {
-					name = name[:100]
+				body := logName(record.GetBody())
+				name := body
+				if len(name) > maxLogName {
+					name = name[:maxLogName]
 				}
 				log := log.With(slog.String("name", name))
-				for _, match := range s.matches {
-					if !match.isMatch(record.GetBody().String(), record.GetAttributes()) {
+				item := Item{
+					Signal:       "log",
+					Service:      service,
+					Scope:        scope.GetScope().GetName(),
+					ScopeVersion: scope.GetScope().GetVersion(),
+					Name:         name,
+					Attributes:   record.GetAttributes(),
+					Resource:     r.GetResource().GetAttributes(),
+					Profile:      profile,
+					Session:      session,
+				}
+				for _, match := range matches {
+					if !match.isMatch(body, record.GetAttributes()) {
 						continue
 					}
 
-					missing := match.compareAttributes(log, record.GetAttributes(), scope.GetScope().GetAttributes(), r.GetResource().GetAttributes())
-					found = true
-					count += missing
-					if missing > 0 {
-						names = append(names, fmt.Sprintf("%s/%s", scope.Scope.GetName(), name))
-					}
+					findings := match.compareAttributes(record.GetAttributes(), scope.GetScope().GetAttributes(), r.GetResource().GetAttributes())
+					// Observers see the baselined findings, so they count
+					// against compliance.
+					kept := cfg.baseline.filter(item, findings)
+					logFindings(log, kept)
+					item.Matched = true
+					item.Expected = append(item.Expected, match.expected...)
+					item.Groups = append(item.Groups, match.groups...)
+					item.Findings = append(item.Findings, findings...)
+					item.Failed = item.Failed || len(match.policy.failures(kept)) > 0
+					res.add(record, match.policy, kept)
 				}
-				if !found && s.reportUnmatched {
+				if !item.Matched && cfg.reportUnmatched {
 					log.Info("unmatched log")
 				}
+				s.observers.observe(item)
 			}
 		}
 	}
 
-	if count > 0 && !s.disableError {
+	if rejected, msg, ok := res.partialSuccess(); ok {
 		return &pbCollectorLogs.ExportLogsServiceResponse{
 			PartialSuccess: &pbCollectorLogs.ExportLogsPartialSuccess{
-				RejectedLogRecords: int64(count),
-				ErrorMessage:       "missing attributes",
+				RejectedLogRecords: rejected,
+				ErrorMessage:       msg,
 			},
-		}, status.Error(codes.FailedPrecondition, fmt.Sprintf("missing attributes: %v", names))
+		}, res.err()
 	}
 
 	return &pbCollectorLogs.ExportLogsServiceResponse{}, nil
 }
+
+// logName is the name of a log record, its body if it is a string and
+// otherwise the body as JSON. Unlike the text format of the protos it is the
+// same in every build, so it can be recorded in a baseline.
+func logName(body *v1.AnyValue) string {
+	if s, ok := body.GetValue().(*v1.AnyValue_StringValue); ok {
+		return s.StringValue
+	}
+	if body.GetValue() == nil {
+		return ""
+	}
+	data, err := json.Marshal(jsonValue(body))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// jsonValue converts the value to one encoding/json marshals, the keys of a
+// map are sorted by the encoding.
+func jsonValue(value *v1.AnyValue) any {
+	switch v := value.GetValue().(type) {
+	case *v1.AnyValue_StringValue:
+		return v.StringValue
+	case *v1.AnyValue_IntValue:
+		return v.IntValue
+	case *v1.AnyValue_DoubleValue:
+		return v.DoubleValue
+	case *v1.AnyValue_BoolValue:
+		return v.BoolValue
+	case *v1.AnyValue_BytesValue:
+		return v.BytesValue
+	case *v1.AnyValue_ArrayValue:
+		values := []any{}
+		for _, e := range v.ArrayValue.GetValues() {
+			values = append(values, jsonValue(e))
+		}
+		return values
+	case *v1.AnyValue_KvlistValue:
+		kvs := map[string]any{}
+		for _, kv := range v.KvlistValue.GetValues() {
+			kvs[kv.GetKey()] = jsonValue(kv.GetValue())
+		}
+		return kvs
+	}
+	return nil
+}
@@ -4,9 +4,11 @@ package servers
 
 import (
 	"context"
+	"regexp"
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/otel/attribute"
 	pbCollectorLogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
 	common "go.opentelemetry.io/proto/otlp/common/v1"
@@ -15,9 +17,9 @@ import (
 )
 
 func TestLogsServerExport(t *testing.T) {
-	defaultServer := &LogServer{
+	defaultServer := newTestLogServer(&signalConfig{
 		matches: []matchDef{newTestMatchDef([]string{"test"}, nil)},
-	}
+	})
 	testCases := []struct {
 		name       string
 		traceAttrs []attribute.KeyValue
@@ -52,14 +54,22 @@ func TestLogsServerExport(t *testing.T) {
 			},
 		},
 		{
-			name: "Disable Error",
+			name: "Accept",
 			traceAttrs: []attribute.KeyValue{
 				attribute.String("notTest", "test"),
 			},
-			server: &LogServer{
-				matches:      []matchDef{newTestMatchDef([]string{"test"}, nil)},
-				disableError: true,
+			server: newTestLogServer(&signalConfig{
+				matches: []matchDef{newTestActionMatchDef([]string{"test"}, Accept)},
+			}),
+		},
+		{
+			name: "Partial Success",
+			traceAttrs: []attribute.KeyValue{
+				attribute.String("notTest", "test"),
 			},
+			server: newTestLogServer(&signalConfig{
+				matches: []matchDef{newTestActionMatchDef([]string{"test"}, PartialSuccess)},
+			}),
 		},
 		{
 			name: "Match Scope Attrs",
@@ -88,8 +98,8 @@ func TestLogsServerExport(t *testing.T) {
 					t.Fatal("expected error, got nil")
 				}
 				errMsg := err.Error()
-				if !strings.Contains(errMsg, "TestScope") {
-					t.Errorf("expected error to contain %q, got %q", "TestScope", errMsg)
+				if !strings.Contains(errMsg, "test.group: test (1)") {
+					t.Errorf("expected error to contain %q, got %q", "test.group: test (1)", errMsg)
 				}
 			} else {
 				if err != nil {
@@ -100,6 +110,55 @@ func TestLogsServerExport(t *testing.T) {
 	}
 }
 
+func TestLogName(t *testing.T) {
+	assert.Equal(t, "", logName(nil))
+	assert.Equal(t, "hello", logName(createValue("hello")))
+	assert.Equal(t, "42", logName(&common.AnyValue{Value: &common.AnyValue_IntValue{IntValue: 42}}))
+	assert.Equal(t, `{"a":[true,1.5],"b":"c"}`, logName(&common.AnyValue{Value: &common.AnyValue_KvlistValue{KvlistValue: &common.KeyValueList{
+		Values: []*common.KeyValue{
+			{Key: "b", Value: createValue("c")},
+			{Key: "a", Value: &common.AnyValue{Value: &common.AnyValue_ArrayValue{ArrayValue: &common.ArrayValue{Values: []*common.AnyValue{
+				{Value: &common.AnyValue_BoolValue{BoolValue: true}},
+				{Value: &common.AnyValue_DoubleValue{DoubleValue: 1.5}},
+			}}}}},
+		},
+	}}}))
+}
+
+func TestLogsServerMatchBody(t *testing.T) {
+	kvlist := &common.AnyValue{Value: &common.AnyValue_KvlistValue{KvlistValue: &common.KeyValueList{
+		Values: []*common.KeyValue{{Key: "a", Value: &common.AnyValue{Value: &common.AnyValue_IntValue{IntValue: 1}}}},
+	}}}
+	testCases := []struct {
+		name     string
+		match    string
+		body     *common.AnyValue
+		hasError bool
+	}{
+		{name: "string body", match: `^hello$`, body: createValue("hello"), hasError: true},
+		{name: "string body no match", match: `^string_value`, body: createValue("hello")},
+		{name: "structured body", match: `^\{"a":1\}$`, body: kvlist, hasError: true},
+		{name: "structured body no match", match: `kvlist_value`, body: kvlist},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			match := newTestMatchDef([]string{"test"}, nil)
+			match.name = regexp.MustCompile(tc.match)
+			ts := newTestLogServer(&signalConfig{matches: []matchDef{match}})
+
+			req := newLogsRequest(nil, nil, nil)
+			req.ResourceLogs[0].ScopeLogs[0].LogRecords[0].Body = tc.body
+			_, err := ts.Export(context.Background(), req)
+			if tc.hasError {
+				assert.Error(t, err, "the match selects the record")
+			} else {
+				assert.NoError(t, err, "the match doesn't select the record")
+			}
+		})
+	}
+}
+
 func newLogsRequest(logsAttrs, scopeAttrs, resAttrs []attribute.KeyValue) *pbCollectorLogs.ExportLogsServiceRequest {
 	tAttrs := createKeyValues(logsAttrs)
 	sAttrs := createKeyValues(scopeAttrs)
@@ -133,3 +192,9 @@ func newLogsRequest(logsAttrs, scopeAttrs, resAttrs []attribute.KeyValue) *pbCol
 		},
 	}
 }
+
+func newTestLogServer(cfg *signalConfig) *LogServer {
+	s := &LogServer{}
+	s.config.Store(cfg)
+	return s
+}
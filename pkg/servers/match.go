@@ -3,48 +3,305 @@
 package servers
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
 
 	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
 	v1 "go.opentelemetry.io/proto/otlp/common/v1"
 )
 
+// includeGroup is the group of findings for attributes from the include list.
+const includeGroup = "include"
+
 type matchDef struct {
 	name   *regexp.Regexp
 	attrs  map[string]string
 	semVer *string
-	group  []string
-	ignore []string
+	group  []semconv.Attribute
+	// groups are the configured group ids.
+	groups []string
+
+	// include holds the wildcard include entries, exact entries are part of group.
+	include []pattern
+	ignore  []pattern
+	// assertions check the values of attributes.
+	assertions []assertion
+	// spanName is the span name convention, nil if there is none.
+	spanName *spanName
+	// registry is used to lint attribute keys, nil unless lint is set.
+	registry *registry
+	// shared are the ignore entries of the config and of the service.
+	shared []pattern
+
+	// levels is the requirement level of each attribute in group.
+	levels map[string]semconv.RequirementLevel
+	// sources is the group id each attribute in group came from.
+	sources map[string]string
+	// warned are the opt in attributes with a cardinality warning.
+	warned map[string]bool
+	// stability is the stability of each attribute in group in its group.
+	stability map[string]string
+	// experimental is how attributes that aren't stable are checked.
+	experimental string
+	// excluded are the attributes of the groups left out for their
+	// stability, they aren't extra either.
+	excluded map[string]bool
+	// expected are the attributes checked, ignored attributes are left out.
+	expected []Expected
+	policy   policy
+
+	// section names where the match was configured, e.g. trace[0].
+	section string
 
 	reportAdditional bool
 }
 
-func newMatchDef(m Match, g map[string]semconv.Group) matchDef {
+func newMatchDef(section string, m Match, shared []pattern, signalPolicy ErrorPolicy, svs map[string]semconv.SemanticVersion) (matchDef, error) {
+	errs := m.ErrorPolicy.validate(section + ": error_policy")
+
 	semver := new(string)
-	if m.SemanticVersion != "" {
-		*semver = m.SemanticVersion
+	*semver = m.SemanticVersion
+	if *semver == "" {
+		*semver = semconv.DefaultVersion
+	}
+	sv, found := svs[*semver]
+	if !found {
+		errs = append(errs, fmt.Errorf("%s: %w", section, unknownError("semantic_version", *semver, mapKeys(svs))))
 	}
+	g := sv.Groups
+
 	var reg *regexp.Regexp
 	if m.Match != "" {
-		reg = regexp.MustCompile(m.Match)
+		var err error
+		reg, err = regexp.Compile(m.Match)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid match %q: %w", section, m.Match, err))
+		}
+	}
+	known := map[string]semconv.Attribute{}
+	templates := map[string]bool{}
+	for _, group := range g {
+		for _, attr := range group.Attributes {
+			known[attr.CanonicalId] = attr
+			if attr.Type.IsTemplate() {
+				templates[attr.CanonicalId] = true
+			}
+		}
+	}
+	experimental, err := newExperimental(section, m.Experimental)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if found && experimental != experimentalCheck && !sv.HasStability() {
+		// Every attribute would be experimental, so nothing would be
+		// checked or fail.
+		errs = append(errs, fmt.Errorf("%s: experimental: %s needs a semantic_version that marks attributes stable, %s doesn't", section, experimental, *semver))
 	}
-	groups := []semconv.Group{}
+	attributes := []semconv.Attribute{}
+	levels := map[string]semconv.RequirementLevel{}
+	sources := map[string]string{}
+	warned := map[string]bool{}
+	stability := map[string]string{}
+	excluded := map[string]bool{}
 	for _, group := range m.Groups {
-		groups = append(groups, g[group])
+		grp, ok := g[group]
+		switch {
+		case group == "":
+			errs = append(errs, fmt.Errorf("%s: groups: empty entry", section))
+		case !ok && found:
+			errs = append(errs, fmt.Errorf("%s: %w", section, unknownError("group", group, mapKeys(g))))
+		}
+		for _, attr := range grp.Attributes {
+			s := grp.AttributeStability(attr)
+			if experimental == experimentalExclude && s != semconv.Stable {
+				excluded[attr.CanonicalId] = true
+				continue
+			}
+			if _, ok := levels[attr.CanonicalId]; !ok {
+				levels[attr.CanonicalId] = attr.Level()
+				sources[attr.CanonicalId] = group
+				stability[attr.CanonicalId] = s
+				if attr.Level() == semconv.OptIn && attr.CardinalityWarning() {
+					warned[attr.CanonicalId] = true
+				}
+			}
+			attributes = append(attributes, attr)
+		}
+	}
+	include := []pattern{}
+	patterns, perrs := newPatterns(section+": include", m.Include, templates)
+	errs = append(errs, perrs...)
+	for _, p := range patterns {
+		// Attributes are included because they are expected.
+		levels[p.raw] = semconv.Required
+		if _, ok := sources[p.raw]; !ok {
+			sources[p.raw] = includeGroup
+		}
+		if p.isWildcard() {
+			include = append(include, p)
+			continue
+		}
+		attr, ok := known[p.raw]
+		if !ok {
+			attr = semconv.Attribute{CanonicalId: p.raw}
+		}
+		attributes = append(attributes, attr)
+	}
+	ignore, perrs := newPatterns(section+": ignore", m.Ignore, templates)
+	errs = append(errs, perrs...)
+	sharedIgnore := []pattern{}
+	for _, p := range shared {
+		sharedIgnore = append(sharedIgnore, p.withTemplates(templates))
 	}
 	attrs := map[string]string{}
 	for _, attr := range m.MatchAttributes {
+		if attr.Name == "" {
+			errs = append(errs, fmt.Errorf("%s: match_attributes: empty name", section))
+		}
 		attrs[attr.Name] = attr.Value
 	}
+	assertions, aerrs := newAssertions(section, m.Assertions)
+	errs = append(errs, aerrs...)
+	spanName, serrs := newSpanName(section, m)
+	errs = append(errs, serrs...)
+	if err := errors.Join(errs...); err != nil {
+		return matchDef{}, err
+	}
+	for name := range levels {
+		delete(excluded, name)
+	}
+	expected := []Expected{}
+	for name, level := range levels {
+		ignored := func(p pattern) bool { return p.matches(name) }
+		if slices.ContainsFunc(ignore, ignored) || slices.ContainsFunc(sharedIgnore, ignored) {
+			continue
+		}
+		expected = append(expected, Expected{Group: sources[name], Attribute: name, Level: level})
+	}
+	slices.SortFunc(expected, func(a, b Expected) int { return strings.Compare(a.Attribute, b.Attribute) })
+	var lintRegistry *registry
+	if m.Lint {
+		lintRegistry = newRegistry(sv.Registry)
+	}
 	return matchDef{
 		name:             reg,
 		semVer:           semver,
 		attrs:            attrs,
-		group:            append(semconv.GetAttributes(groups...), m.Include...),
-		ignore:           m.Ignore,
+		group:            attributes,
+		groups:           m.Groups,
+		include:          include,
+		ignore:           ignore,
+		assertions:       assertions,
+		spanName:         spanName,
+		registry:         lintRegistry,
+		shared:           sharedIgnore,
+		levels:           levels,
+		sources:          sources,
+		warned:           warned,
+		stability:        stability,
+		experimental:     experimental,
+		excluded:         excluded,
+		expected:         expected,
+		policy:           newPolicy(signalPolicy.merge(m.ErrorPolicy)),
+		section:          section,
 		reportAdditional: m.ReportAdditional,
+	}, nil
+}
+
+// newMatchDefs compiles the matches of a signal, e.g. trace.
+func newMatchDefs(signal string, matches []Match, shared []pattern, signalPolicy ErrorPolicy, svs map[string]semconv.SemanticVersion) ([]matchDef, error) {
+	defs := []matchDef{}
+	errs := []error{}
+	for i, match := range matches {
+		def, err := newMatchDef(fmt.Sprintf("%s[%d]", signal, i), match, shared, signalPolicy, svs)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		defs = append(defs, def)
 	}
+	return defs, errors.Join(errs...)
+}
+
+// signalConfig is the compiled config of a server. It is replaced as a whole
+// on reload, so an Export call always sees a consistent config.
+type signalConfig struct {
+	resource        matchDef
+	matches         []matchDef
+	services        []serviceMatches
+	reportUnmatched bool
+	baseline        *baseline
+	// cardinality are the limits and unbounded attributes, the zero config
+	// has none.
+	cardinality cardinalityConfig
+	// profiles are the compiled profiles, they share the baseline.
+	profiles map[string]*signalConfig
+}
+
+func newSignalConfig(cfg Config, signal string, svs map[string]semconv.SemanticVersion) (*signalConfig, error) {
+	c, err := compileSignalConfig(cfg, signal, svs)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Baseline != "" {
+		b, err := ReadBaseline(cfg.Baseline)
+		if err != nil {
+			return nil, err
+		}
+		c.baseline = newBaseline(b, signal)
+	}
+	for _, name := range profileNames(cfg.Profiles) {
+		p, err := compileSignalConfig(cfg.withProfile(cfg.Profiles[name]), signal, svs)
+		if err != nil {
+			return nil, profileError(name, err)
+		}
+		p.baseline = c.baseline
+		c.profiles[name] = p
+	}
+	return c, nil
+}
+
+// compileSignalConfig compiles the matches of the signal, without the
+// baseline and profiles.
+func compileSignalConfig(cfg Config, signal string, svs map[string]semconv.SemanticVersion) (*signalConfig, error) {
+	global, errs := sharedPatterns("global", "ignore", cfg.Ignore)
+	errs = append(errs, cfg.validatePolicies(signal)...)
+	cardinality, cerrs := newCardinalityConfig(cfg.Cardinality)
+	errs = append(errs, cerrs...)
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	matches := cfg.forSignal(signal)
+	signalPolicy := cfg.signalPolicy(signal)
+	resource, err := newMatchDef("resource", cfg.Resource, global, signalPolicy, svs)
+	if err != nil {
+		return nil, err
+	}
+	defs, err := newMatchDefs(signal, matches, global, signalPolicy, svs)
+	if err != nil {
+		return nil, err
+	}
+	services := []serviceMatches{}
+	for i, svc := range cfg.Services {
+		sm, err := newServiceMatches(i, svc, signal, matches, global, signalPolicy, svs)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, sm)
+	}
+	return &signalConfig{
+		resource:        resource,
+		matches:         defs,
+		services:        services,
+		reportUnmatched: cfg.ReportUnmatched,
+		cardinality:     cardinality,
+		profiles:        map[string]*signalConfig{},
+	}, nil
 }
 
 func (m matchDef) isMatch(name string, attrs []*v1.KeyValue) bool {
@@ -59,13 +316,23 @@ func (m matchDef) isNameMatch(name string) bool {
 }
 
 func (m matchDef) isAttrMatch(attrs []*v1.KeyValue) bool {
-	if len(m.attrs) == 0 {
+	return attrsMatch(m.attrs, attrs)
+}
+
+// attrsMatch reports if attrs has every wanted attribute, an empty value
+// matches any value. Values that aren't strings are compared in their config
+// form, e.g. "200" or "true".
+func attrsMatch(want map[string]string, attrs []*v1.KeyValue) bool {
+	if len(want) == 0 {
 		return true
 	}
-	for key, val := range m.attrs {
+	for key, val := range want {
 		found := false
 		for _, attr := range attrs {
-			if attr.Key == key && (val == "" || attr.Value.GetStringValue() == val) {
+			if attr.Key != key {
+				continue
+			}
+			if s, ok := valueString(attr.Value); val == "" || (ok && s == val) {
 				found = true
 				break
 			}
@@ -77,34 +344,147 @@ func (m matchDef) isAttrMatch(attrs []*v1.KeyValue) bool {
 	return true
 }
 
-func (m matchDef) compareAttributes(log *slog.Logger, attrs ...[]*v1.KeyValue) int {
-	missing, extra := semconv.Compare(m.group, attrs...)
-	missing, extra = filter(missing, m.ignore), filter(extra, m.ignore)
+// compareAttributes returns the findings for the attributes.
+func (m matchDef) compareAttributes(attrs ...[]*v1.KeyValue) []Finding {
+	missing, extra, invalid := semconv.Compare(m.group, attrs...)
+	missing, extra = m.compareIncludes(missing, extra, attrs...)
+	extra = slices.DeleteFunc(extra, func(name string) bool { return m.excluded[name] })
+	mismatched := m.checkAssertions(attrs...)
+	missing, extra, invalid = m.filter(missing), m.filter(extra), m.filter(invalid)
+	mismatched = m.filter(mismatched)
 
-	m.logAttributes(log, missing, extra)
+	findings := []Finding{}
+	for _, name := range missing {
+		findings = append(findings, m.finding(m.sources[name], name, missingCategory(m.levels[name])))
+	}
+	for _, name := range invalid {
+		findings = append(findings, m.finding(m.sources[name], name, TypeMismatch))
+	}
+	for _, name := range mismatched {
+		group, ok := m.sources[name]
+		if !ok {
+			group = assertionGroup
+		}
+		findings = append(findings, m.finding(group, name, ValueMismatch))
+	}
+	if m.reportAdditional {
+		for _, name := range extra {
+			findings = append(findings, Finding{Attribute: name, Category: Extra})
+		}
+	}
+	return append(findings, m.lint(attrs...)...)
+}
 
-	return len(missing)
+// logFindings logs the missing, incorrect and extra attributes. Advisory
+// findings are logged apart, with advisory set.
+func logFindings(log *slog.Logger, findings []Finding) {
+	enforced, advisory := []Finding{}, []Finding{}
+	for _, f := range findings {
+		if f.Advisory {
+			advisory = append(advisory, f)
+		} else {
+			enforced = append(enforced, f)
+		}
+	}
+	logCategories(log, enforced)
+	logCategories(log.With(slog.Bool("advisory", true)), advisory)
 }
 
-func (m matchDef) logAttributes(log *slog.Logger, missing, extra []string) {
+// logCategories logs the findings, one message per category.
+func logCategories(log *slog.Logger, findings []Finding) {
+	var missing, extra, invalid, mismatched, high, naming, unknown []string
+	suggestions := map[string]string{}
+	for _, f := range findings {
+		switch f.Category {
+		case TypeMismatch:
+			invalid = append(invalid, f.Attribute)
+		case ValueMismatch:
+			mismatched = append(mismatched, f.Attribute)
+		case HighCardinality:
+			high = append(high, f.Attribute)
+		case SpanNameMismatch:
+			// Logged with the expected name by the trace server.
+		case Naming:
+			naming = append(naming, f.Attribute)
+		case UnknownAttribute:
+			unknown = append(unknown, f.Attribute)
+			if f.Suggestion != "" {
+				suggestions[f.Attribute] = f.Suggestion
+			}
+		case Extra:
+			extra = append(extra, f.Attribute)
+		default:
+			missing = append(missing, f.Attribute)
+		}
+	}
 	if len(missing) > 0 {
 		log.Info("missing attributes",
 			slog.Any("attributes", missing),
 		)
 	}
-	if len(extra) > 0 && m.reportAdditional {
+	if len(invalid) > 0 {
+		log.Info("incorrect attribute types",
+			slog.Any("attributes", invalid),
+		)
+	}
+	if len(mismatched) > 0 {
+		log.Info("incorrect attribute values",
+			slog.Any("attributes", mismatched),
+		)
+	}
+	if len(high) > 0 {
+		log.Info("high cardinality attributes",
+			slog.Any("attributes", high),
+		)
+	}
+	if len(naming) > 0 {
+		log.Info("attribute names don't follow the naming rules",
+			slog.Any("attributes", naming),
+		)
+	}
+	if len(unknown) > 0 {
+		log.Info("unknown attributes",
+			slog.Any("attributes", unknown),
+			slog.Any("suggestions", suggestions),
+		)
+	}
+	if len(extra) > 0 {
 		log.Info("extra attributes",
 			slog.Any("attributes", extra),
 		)
 	}
 }
 
-func filter(input, removed []string) []string {
+// compareIncludes checks the wildcard include entries. An entry is missing
+// when no attribute matches it, and the attributes it matches are not extra.
+func (m matchDef) compareIncludes(missing, extra []string, attrs ...[]*v1.KeyValue) ([]string, []string) {
+	for _, inc := range m.include {
+		found := false
+		for _, aList := range attrs {
+			for _, a := range aList {
+				found = found || inc.match(a.Key)
+			}
+		}
+		if !found {
+			missing = append(missing, inc.raw)
+		}
+		extra = slices.DeleteFunc(extra, inc.match)
+	}
+	return missing, extra
+}
+
+// filter removes the ignored attributes.
+func (m matchDef) filter(input []string) []string {
 	output := []string{}
 OUTER:
 	for _, in := range input {
-		for _, rem := range removed {
-			if in == rem {
+		for _, rem := range m.ignore {
+			if rem.match(in) {
+				continue OUTER
+			}
+		}
+		for _, rem := range m.shared {
+			if rem.match(in) {
 				continue OUTER
 			}
 		}
@@ -112,3 +492,66 @@ OUTER:
 	}
 	return output
 }
+
+// eachIgnore calls fn with the ignore entries of the match and the section
+// each was configured in.
+func (m matchDef) eachIgnore(fn func(section string, p pattern)) {
+	for _, p := range m.ignore {
+		fn(m.section, p)
+	}
+	for _, p := range m.shared {
+		fn(p.section, p)
+	}
+}
+
+// eachIgnore calls fn with the ignore entries of all matches, including
+// those of the services and profiles. Shared entries are passed once per
+// match.
+func (c *signalConfig) eachIgnore(fn func(section string, p pattern)) {
+	for _, m := range c.matches {
+		m.eachIgnore(fn)
+	}
+	for _, s := range c.services {
+		for _, m := range s.matches {
+			m.eachIgnore(fn)
+		}
+	}
+	for _, p := range c.profiles {
+		p.eachIgnore(fn)
+	}
+}
+
+func (c *signalConfig) ignoreUsage() []IgnoreUsage {
+	usage := []IgnoreUsage{}
+	c.eachIgnore(func(section string, p pattern) {
+		usage = append(usage, IgnoreUsage{Section: section, Pattern: p.raw, Hits: p.hits.Load()})
+	})
+	return usage
+}
+
+// carryIgnoreHits adds the hits of the ignore entries of prev to the same
+// entries of c, so the entries used before a reload aren't reported as
+// unused. prev may be nil.
+func (c *signalConfig) carryIgnoreHits(prev *signalConfig) {
+	if prev == nil {
+		return
+	}
+	type key struct{ section, pattern string }
+	hits := map[key]int64{}
+	counted := map[*atomic.Int64]bool{}
+	prev.eachIgnore(func(section string, p pattern) {
+		if p.hits == nil || counted[p.hits] {
+			return
+		}
+		counted[p.hits] = true
+		hits[key{section, p.raw}] += p.hits.Load()
+	})
+	carried := map[*atomic.Int64]bool{}
+	c.eachIgnore(func(section string, p pattern) {
+		if p.hits == nil || carried[p.hits] {
+			return
+		}
+		carried[p.hits] = true
+		p.hits.Add(hits[key{section, p.raw}])
+	})
+}
@@ -6,18 +6,34 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "go.opentelemetry.io/proto/otlp/common/v1"
 )
 
 func newTestMatchDef(groups []string, ignore []string) matchDef {
+	attrs := []semconv.Attribute{}
+	sources := map[string]string{}
+	for _, g := range groups {
+		attrs = append(attrs, semconv.Attribute{CanonicalId: g})
+		sources[g] = "test.group"
+	}
 	return matchDef{
-		name:   regexp.MustCompile(`.*`),
-		group:  groups,
-		ignore: ignore,
+		name:    regexp.MustCompile(`.*`),
+		group:   attrs,
+		sources: sources,
+		ignore:  newTestPatterns(ignore, nil),
+		policy:  newPolicy(defaultPolicy),
 	}
 }
 
+func newTestActionMatchDef(groups []string, action Action) matchDef {
+	m := newTestMatchDef(groups, nil)
+	m.policy.action = action
+	return m
+}
+
 func Test_matchDef_isMatch(t *testing.T) {
 	matchDefFilled := matchDef{
 		name:  regexp.MustCompile(`foo`),
@@ -204,3 +220,46 @@ func createKeyValue(key, value string) *v1.KeyValue {
 		Value: &v1.AnyValue{Value: &v1.AnyValue_StringValue{StringValue: value}},
 	}
 }
+
+func Test_matchDef_filter(t *testing.T) {
+	m := matchDef{
+		ignore: newTestPatterns([]string{"http.request.header", "host.id"}, map[string]bool{"http.request.header": true}),
+		shared: newTestPatterns([]string{"process.*"}, nil),
+	}
+	got := m.filter([]string{
+		"host.id",
+		"host.name",
+		"http.request.header",
+		"http.request.header.accept",
+		"http.request.headers",
+		"process.pid",
+	})
+	assert.Equal(t, []string{"host.name", "http.request.headers"}, got)
+}
+
+func Test_matchDef_compareIncludes(t *testing.T) {
+	m := matchDef{
+		include: newTestPatterns([]string{"telemetry.sdk.*", "/^custom\\./"}, nil),
+	}
+	attrs := []*v1.KeyValue{
+		createKeyValue("telemetry.sdk.name", "opentelemetry"),
+		createKeyValue("service.name", "test"),
+	}
+	missing, extra := m.compareIncludes(nil, []string{"telemetry.sdk.name", "service.name"}, attrs)
+	assert.Equal(t, []string{"/^custom\\./"}, missing)
+	assert.Equal(t, []string{"service.name"}, extra)
+}
+
+func TestNewMatchDefExpected(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	m, err := newMatchDef("trace[0]", Match{
+		Include: []string{"app.id", "app.secret"},
+		Ignore:  []string{"app.secret"},
+	}, nil, defaultPolicy, svs)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Expected{{Group: includeGroup, Attribute: "app.id", Level: semconv.Required}}, m.expected)
+	assert.Equal(t, int64(0), m.ignore[0].hits.Load(), "building expected must not count ignore hits")
+}
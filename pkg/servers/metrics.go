@@ -6,78 +6,72 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
 
 	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
 	pbCollectorMetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
 	v1 "go.opentelemetry.io/proto/otlp/common/v1"
 	pbMetrics "go.opentelemetry.io/proto/otlp/metrics/v1"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 type MetricsServer struct {
 	pbCollectorMetrics.UnimplementedMetricsServiceServer
 
-	resource        matchDef
-	matches         []matchDef
-	reportUnmatched bool
-
-	disableError bool
+	config      atomic.Pointer[signalConfig]
+	observers   observers
+	cardinality *cardinalityTracker
 }
 
-func NewMetricsService(cfg Config, svs map[string]semconv.SemanticVersion) *MetricsServer {
-	_, found := svs[cfg.Resource.SemanticVersion]
-	if cfg.Resource.SemanticVersion != "" && !found {
-		cfg.Resource.SemanticVersion = semconv.DefaultVersion
+func NewMetricsService(cfg Config, svs map[string]semconv.SemanticVersion, obs ...Observer) (*MetricsServer, error) {
+	s := &MetricsServer{observers: obs, cardinality: newCardinalityTracker()}
+	if err := s.Update(cfg, svs); err != nil {
+		return nil, err
 	}
+	return s, nil
+}
 
-	resSemVer := cfg.Resource.SemanticVersion
-	if !found {
-		resSemVer = semconv.DefaultVersion
+// Update replaces the config of the server. If the new config is invalid the
+// previous config is kept. The hits of ignore entries carry over to the same
+// entries of the new config.
+func (s *MetricsServer) Update(cfg Config, svs map[string]semconv.SemanticVersion) error {
+	c, err := newSignalConfig(cfg, "metrics", svs)
+	if err != nil {
+		return err
 	}
-	resource := newMatchDef(cfg.Resource, svs[resSemVer].Groups)
+	c.carryIgnoreHits(s.config.Swap(c))
+	return nil
+}
 
-	matches := []matchDef{}
-	for _, match := range cfg.Metrics {
-		groups, ok := svs[match.SemanticVersion]
-		if !ok {
-			match.SemanticVersion = semconv.DefaultVersion
-		}
-		matches = append(matches, newMatchDef(match, groups.Groups))
-	}
+// IgnoreUsage reports how many attributes each ignore entry has matched.
+func (s *MetricsServer) IgnoreUsage() []IgnoreUsage {
+	return s.config.Load().ignoreUsage()
+}
 
-	return &MetricsServer{
-		resource:        resource,
-		matches:         matches,
-		reportUnmatched: cfg.ReportUnmatched,
-		disableError:    cfg.DisableError,
-	}
+// FixedBaseline returns the baseline entries that were checked and no longer
+// found.
+func (s *MetricsServer) FixedBaseline() []BaselineEntry {
+	return s.config.Load().baseline.fixed()
 }
 
 func (s *MetricsServer) Export(ctx context.Context, req *pbCollectorMetrics.ExportMetricsServiceRequest) (*pbCollectorMetrics.ExportMetricsServiceResponse, error) {
 	if req == nil {
 		return nil, nil
 	}
-	count := 0
-	names := []string{}
+	profile, session := requestHeaders(ctx)
+	cfg, err := s.config.Load().forProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	res := newResult("data point")
 	for _, r := range req.ResourceMetrics {
 		log := slog.With("type", "metrics")
 		if schema := r.GetSchemaUrl(); schema != "" {
 			log = log.With("resource.schema", schema)
 		}
-		if attr := r.Resource.GetAttributes(); len(attr) > 0 {
-			name := ""
-			for _, kv := range attr {
-				if kv.Key == "service.name" {
-					name = kv.Value.GetStringValue()
-				}
-				if name == "" {
-					name = kv.String()
-				}
-			}
-			if name != "" {
-				log = log.With("service.name", name)
-			}
+		service := serviceName(r.GetResource().GetAttributes())
+		matches := cfg.matchesFor(r.GetResource().GetAttributes())
+		if service != "" {
+			log = log.With("service.name", service)
 		}
 
 		for _, scope := range r.ScopeMetrics {
@@ -95,75 +89,95 @@ func (s *MetricsServer) Export(ctx context.Context, req *pbCollectorMetrics.Expo
 			}
 
 			for _, metric := range scope.Metrics {
-				found := false
 				log := log.With(slog.String("name", metric.Name))
 				if url := scope.GetSchemaUrl(); url != "" {
 					log = log.With(slog.String("schema", url))
 				}
-				log.Error("Got metric")
-
-				for _, match := range s.matches {
-					missing, matched := checkMetric(log, match, metric, scope.GetScope(), r.GetResource())
-					found = found || matched
-					count += missing
-					if missing > 0 {
-						names = append(names, fmt.Sprintf("%s/%s", scope.Scope.GetName(), metric.GetName()))
+
+				found := false
+				for _, p := range dataPoints(log, metric) {
+					item := Item{
+						Signal:       "metrics",
+						Service:      service,
+						Scope:        scope.GetScope().GetName(),
+						ScopeVersion: scope.GetScope().GetVersion(),
+						Name:         metric.GetName(),
+						Attributes:   p.GetAttributes(),
+						Resource:     r.GetResource().GetAttributes(),
+						Profile:      profile,
+						Session:      session,
+					}
+					high, tooMany := s.cardinality.observe(cfg.cardinality, service, metric.GetName(), p.GetAttributes())
+					if tooMany {
+						log.Info("too many series", slog.Int("limit", cfg.cardinality.seriesLimit))
 					}
+					for _, match := range matches {
+						if !match.isMatch(metric.GetName(), p.GetAttributes()) {
+							continue
+						}
+						findings := match.compareAttributes(p.GetAttributes(), scope.GetScope().GetAttributes(), r.GetResource().GetAttributes())
+						findings = append(findings, match.cardinalityFindings(high, p.GetAttributes())...)
+						// Observers see the baselined findings, so they count
+						// against compliance.
+						kept := cfg.baseline.filter(item, findings)
+						logFindings(log, kept)
+						item.Matched = true
+						item.Expected = append(item.Expected, match.expected...)
+						item.Groups = append(item.Groups, match.groups...)
+						item.Findings = append(item.Findings, findings...)
+						item.Failed = item.Failed || len(match.policy.failures(kept)) > 0
+						res.add(p, match.policy, kept)
+					}
+					found = found || item.Matched
+					if !item.Matched && len(high) > 0 {
+						log.Info("high cardinality attributes", slog.Any("attributes", high))
+					}
+					s.observers.observe(item)
 				}
-				if !found && s.reportUnmatched {
+				if !found && cfg.reportUnmatched {
 					log.Info("unmatched metric")
 				}
 			}
 		}
 	}
 
-	if count > 0 && !s.disableError {
+	if rejected, msg, ok := res.partialSuccess(); ok {
 		return &pbCollectorMetrics.ExportMetricsServiceResponse{
 			PartialSuccess: &pbCollectorMetrics.ExportMetricsPartialSuccess{
-				RejectedDataPoints: int64(count),
-				ErrorMessage:       "missing attributes",
+				RejectedDataPoints: rejected,
+				ErrorMessage:       msg,
 			},
-		}, status.Error(codes.FailedPrecondition, fmt.Sprintf("missing attributes: %v", names))
+		}, res.err()
 	}
 
 	return &pbCollectorMetrics.ExportMetricsServiceResponse{}, nil
 }
 
-func checkMetric(log *slog.Logger, match matchDef, metric *pbMetrics.Metric, scope, resource attributeGetter) (int, bool) {
-	name := metric.GetName()
-	if !match.isNameMatch(name) {
-		return 0, false
-	}
-
+// dataPoints returns the data points of the metric, whatever its type.
+func dataPoints(log *slog.Logger, metric *pbMetrics.Metric) []attributeGetter {
 	switch d := metric.Data.(type) {
 	case *pbMetrics.Metric_Gauge:
-		return checkDataPoints(log, match, d.Gauge, scope, resource)
+		return toAttributeGetters[*pbMetrics.NumberDataPoint](d.Gauge)
 	case *pbMetrics.Metric_Sum:
-		return checkDataPoints(log, match, d.Sum, scope, resource)
+		return toAttributeGetters[*pbMetrics.NumberDataPoint](d.Sum)
 	case *pbMetrics.Metric_Histogram:
-		return checkDataPoints(log, match, d.Histogram, scope, resource)
+		return toAttributeGetters[*pbMetrics.HistogramDataPoint](d.Histogram)
 	case *pbMetrics.Metric_Summary:
-		return checkDataPoints(log, match, d.Summary, scope, resource)
+		return toAttributeGetters[*pbMetrics.SummaryDataPoint](d.Summary)
 	case *pbMetrics.Metric_ExponentialHistogram:
-		return checkDataPoints(log, match, d.ExponentialHistogram, scope, resource)
+		return toAttributeGetters[*pbMetrics.ExponentialHistogramDataPoint](d.ExponentialHistogram)
 	default:
-		log.Warn("Unsupported metric type: %t", metric.Data)
+		log.Warn("unsupported metric type", slog.String("type", fmt.Sprintf("%T", metric.Data)))
 	}
-	return 0, false
+	return nil
 }
 
-func checkDataPoints[T attributeGetter, D dataPointGetter[T]](log *slog.Logger, match matchDef, metric D, scope, resource attributeGetter) (int, bool) {
-	found := false
-	count := 0
+func toAttributeGetters[T attributeGetter, D dataPointGetter[T]](metric D) []attributeGetter {
+	points := make([]attributeGetter, 0, len(metric.GetDataPoints()))
 	for _, p := range metric.GetDataPoints() {
-		if !match.isAttrMatch(p.GetAttributes()) {
-			continue
-		}
-		missing := match.compareAttributes(log, p.GetAttributes(), scope.GetAttributes(), resource.GetAttributes())
-		found = true
-		count += missing
+		points = append(points, p)
 	}
-	return count, found
+	return points
 }
 
 type attributeGetter interface {
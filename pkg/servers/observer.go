@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+// Item is a span, metric data point or log record checked by a server.
+type Item struct {
+	// Signal is one of trace, metrics or log.
+	Signal       string
+	Service      string
+	Scope        string
+	ScopeVersion string
+	// Name is the span name, metric name or log body, see logName.
+	Name string
+	// Kind is the span kind, e.g. server, empty for other signals.
+	Kind string
+	// Attributes are the attributes of the span, data point or log record,
+	// and Resource those of its resource. Observers must not modify them.
+	Attributes []*v1.KeyValue
+	Resource   []*v1.KeyValue
+	// Profile and Session are from the request headers.
+	Profile string
+	Session string
+	// Matched is set if any match selected the item.
+	Matched bool
+	// Groups are the semantic convention groups of the matches.
+	Groups []string
+	// Expected are the attributes the matches checked for.
+	Expected []Expected
+	// Findings include those in the baseline.
+	Findings []Finding
+	// Failed is set if a finding is in the fail_on categories of its match,
+	// whatever the action. Findings in the baseline don't fail.
+	Failed bool
+}
+
+// Expected is an attribute a match checks for.
+type Expected struct {
+	Group     string
+	Attribute string
+	Level     semconv.RequirementLevel
+}
+
+// Compliant reports if the item has no findings other than extra attributes.
+func (i Item) Compliant() bool {
+	for _, f := range i.Findings {
+		if f.Category != Extra {
+			return false
+		}
+	}
+	return true
+}
+
+// Observer is notified of every item a server checks. It must be safe to
+// call concurrently.
+type Observer interface {
+	Observe(Item)
+}
+
+type observers []Observer
+
+func (o observers) observe(item Item) {
+	for _, obs := range o {
+		obs.Observe(item)
+	}
+}
+
+// serviceName is the service.name resource attribute. Without one the first
+// attribute is used so the resource can still be told apart.
+func serviceName(attrs []*v1.KeyValue) string {
+	name := ""
+	for _, kv := range attrs {
+		if kv.Key == "service.name" {
+			name = kv.Value.GetStringValue()
+		}
+		if name == "" {
+			name = kv.String()
+		}
+	}
+	return name
+}
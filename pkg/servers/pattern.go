@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// pattern is an entry of an ignore or include list. It is either an attribute
+// name, a glob where `*` matches any characters (e.g. process.*), or a regular
+// expression wrapped in slashes (e.g. /^telemetry\.sdk\./).
+type pattern struct {
+	raw string
+	re  *regexp.Regexp
+
+	// template is set when raw names a template attribute, so it also
+	// matches the expanded keys.
+	template bool
+
+	// hits counts the attributes matched, shared between copies of the pattern.
+	hits *atomic.Int64
+	// section is where an ignore entry shared by several matches was
+	// configured, e.g. global or services[0].
+	section string
+}
+
+func newPattern(raw string, templates map[string]bool) (pattern, error) {
+	p := pattern{
+		raw:      raw,
+		template: templates[raw],
+		hits:     &atomic.Int64{},
+	}
+	var err error
+	switch {
+	case raw == "":
+		return p, errors.New("empty entry")
+	case len(raw) > 1 && strings.HasPrefix(raw, "/") && strings.HasSuffix(raw, "/"):
+		p.re, err = regexp.Compile(raw[1 : len(raw)-1])
+	case strings.ContainsAny(raw, "*?"):
+		p.re, err = regexp.Compile(globToRegexp(raw))
+	}
+	if err != nil {
+		return p, fmt.Errorf("invalid pattern %q: %w", raw, err)
+	}
+	return p, nil
+}
+
+// newPatterns compiles the entries of a list, prefixing errors with name, e.g.
+// "trace[0]: ignore".
+func newPatterns(name string, raw []string, templates map[string]bool) ([]pattern, []error) {
+	patterns := []pattern{}
+	errs := []error{}
+	for _, r := range raw {
+		p, err := newPattern(r, templates)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, errs
+}
+
+// sharedPatterns compiles an ignore list shared by several matches.
+func sharedPatterns(section, name string, raw []string) ([]pattern, []error) {
+	patterns, errs := newPatterns(name, raw, nil)
+	for i := range patterns {
+		patterns[i].section = section
+	}
+	return patterns, errs
+}
+
+// withTemplates returns a copy of the pattern that also matches the expanded
+// keys of template attributes. The copy shares the hit count.
+func (p pattern) withTemplates(templates map[string]bool) pattern {
+	p.template = templates[p.raw]
+	return p
+}
+
+func globToRegexp(glob string) string {
+	sb := strings.Builder{}
+	sb.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
+
+// isWildcard reports if the pattern can match more than one attribute name.
+func (p pattern) isWildcard() bool {
+	return p.re != nil
+}
+
+func (p pattern) match(name string) bool {
+	ok := p.matches(name)
+	if ok && p.hits != nil {
+		p.hits.Add(1)
+	}
+	return ok
+}
+
+// matches is match without counting the hit.
+func (p pattern) matches(name string) bool {
+	switch {
+	case p.re != nil:
+		return p.re.MatchString(name)
+	case p.template:
+		return name == p.raw || strings.HasPrefix(name, p.raw+".")
+	}
+	return name == p.raw
+}
+
+// IgnoreUsage is the number of attributes an ignore entry has matched.
+type IgnoreUsage struct {
+	// Section is where the entry was configured, e.g. global or trace[0].
+	Section string
+	Pattern string
+	Hits    int64
+}
+
+// UnusedIgnores merges the ignore usage of the servers and returns the
+// entries that never matched an attribute, keyed by section.
+func UnusedIgnores(usages ...[]IgnoreUsage) map[string][]string {
+	type key struct{ section, pattern string }
+	hits := map[key]int64{}
+	order := []key{}
+	for _, usage := range usages {
+		for _, u := range usage {
+			k := key{u.Section, u.Pattern}
+			if _, ok := hits[k]; !ok {
+				order = append(order, k)
+			}
+			hits[k] += u.Hits
+		}
+	}
+
+	unused := map[string][]string{}
+	for _, k := range order {
+		if hits[k] == 0 {
+			unused[k.section] = append(unused[k.section], k.pattern)
+		}
+	}
+	return unused
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "host.id", name: "host.id", want: true},
+		{pattern: "host.id", name: "host.idx"},
+		{pattern: "process.*", name: "process.runtime.name", want: true},
+		{pattern: "process.*", name: "processor"},
+		{pattern: "host.?d", name: "host.id", want: true},
+		{pattern: "*.name", name: "service.name", want: true},
+		{pattern: `/^telemetry\.sdk\./`, name: "telemetry.sdk.version", want: true},
+		{pattern: `/^telemetry\.sdk\./`, name: "telemetry.auto.version"},
+		{pattern: "http.request.header", name: "http.request.header.accept", want: true},
+	}
+	templates := map[string]bool{"http.request.header": true}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.name, func(t *testing.T) {
+			p, err := newPattern(tt.pattern, templates)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, p.match(tt.name))
+		})
+	}
+}
+
+func TestPatternErrors(t *testing.T) {
+	_, err := newPattern("", nil)
+	assert.Error(t, err)
+	_, err = newPattern("/[a-/", nil)
+	assert.Error(t, err)
+}
+
+func TestUnusedIgnores(t *testing.T) {
+	trace := []IgnoreUsage{
+		{Section: "global", Pattern: "process.*", Hits: 0},
+		{Section: "global", Pattern: "host.id", Hits: 2},
+		{Section: "trace[0]", Pattern: "http.route", Hits: 0},
+	}
+	metrics := []IgnoreUsage{
+		{Section: "global", Pattern: "process.*", Hits: 1},
+		{Section: "global", Pattern: "host.id", Hits: 0},
+	}
+
+	got := UnusedIgnores(trace, metrics)
+	assert.Equal(t, map[string][]string{
+		"trace[0]": {"http.route"},
+	}, got)
+}
+
+func newTestPatterns(raw []string, templates map[string]bool) []pattern {
+	p, errs := newPatterns("test", raw, templates)
+	if len(errs) > 0 {
+		panic(errs)
+	}
+	return p
+}
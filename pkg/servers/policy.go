@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"fmt"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+)
+
+// Action is how a server responds when telemetry doesn't follow the semantic
+// conventions.
+type Action string
+
+const (
+	// Reject returns a FailedPrecondition error with a partial success.
+	Reject Action = "reject"
+	// PartialSuccess returns OK with the partial success populated.
+	PartialSuccess Action = "partial_success"
+	// Accept only logs the findings.
+	Accept Action = "accept"
+)
+
+// severity orders the actions, so the strongest in a request is used.
+func (a Action) severity() int {
+	switch a {
+	case Reject:
+		return 2
+	case PartialSuccess:
+		return 1
+	}
+	return 0
+}
+
+// Category is the kind of a finding.
+type Category string
+
+const (
+	RequiredMissing              Category = "required-missing"
+	ConditionallyRequiredMissing Category = "conditionally-required-missing"
+	RecommendedMissing           Category = "recommended-missing"
+	OptInMissing                 Category = "opt-in-missing"
+	TypeMismatch                 Category = "type-mismatch"
+	ValueMismatch                Category = "value-mismatch"
+	HighCardinality              Category = "high-cardinality"
+	SpanNameMismatch             Category = "span-name"
+	Naming                       Category = "naming"
+	UnknownAttribute             Category = "unknown-attribute"
+	Extra                        Category = "extra"
+)
+
+// missingCategory is the category of a missing attribute with the level.
+func missingCategory(level semconv.RequirementLevel) Category {
+	switch level {
+	case semconv.Required:
+		return RequiredMissing
+	case semconv.ConditionallyRequired:
+		return ConditionallyRequiredMissing
+	case semconv.OptIn:
+		return OptInMissing
+	}
+	return RecommendedMissing
+}
+
+// categoryAliases are the names accepted in fail_on for several categories.
+var categoryAliases = map[string][]Category{
+	"missing": {RequiredMissing, ConditionallyRequiredMissing, RecommendedMissing, OptInMissing},
+}
+
+var categories = map[Category]bool{
+	RequiredMissing:              true,
+	ConditionallyRequiredMissing: true,
+	RecommendedMissing:           true,
+	OptInMissing:                 true,
+	TypeMismatch:                 true,
+	ValueMismatch:                true,
+	HighCardinality:              true,
+	SpanNameMismatch:             true,
+	Naming:                       true,
+	UnknownAttribute:             true,
+	Extra:                        true,
+}
+
+// defaultPolicy fails on any missing attribute, incorrect type or value.
+var defaultPolicy = ErrorPolicy{
+	Action: string(Reject),
+	FailOn: []string{"missing", string(TypeMismatch), string(ValueMismatch)},
+}
+
+// merge returns the policy with the fields set in override replaced.
+func (p ErrorPolicy) merge(override ErrorPolicy) ErrorPolicy {
+	if override.Action != "" {
+		p.Action = override.Action
+	}
+	if override.FailOn != nil {
+		p.FailOn = override.FailOn
+	}
+	return p
+}
+
+// signalPolicy resolves the policy of a signal from the defaults, the
+// disable_error flag and the error_policy section.
+func (c Config) signalPolicy(signal string) ErrorPolicy {
+	p := defaultPolicy
+	if c.DisableError {
+		p.Action = string(Accept)
+	}
+	return p.merge(c.ErrorPolicy.ErrorPolicy).merge(c.ErrorPolicy.forSignal(signal))
+}
+
+func (p ErrorPolicies) forSignal(signal string) ErrorPolicy {
+	switch signal {
+	case "trace":
+		return p.Trace
+	case "metrics":
+		return p.Metrics
+	case "log":
+		return p.Log
+	}
+	return ErrorPolicy{}
+}
+
+// validate checks the action and categories set in the policy, prefixing
+// errors with name, e.g. "error_policy.trace".
+func (p ErrorPolicy) validate(name string) []error {
+	errs := []error{}
+	switch Action(p.Action) {
+	case "", Reject, PartialSuccess, Accept:
+	default:
+		errs = append(errs, fmt.Errorf("%s: unknown action %q", name, p.Action))
+	}
+	for _, c := range p.FailOn {
+		if _, ok := categoryAliases[c]; ok || categories[Category(c)] {
+			continue
+		}
+		candidates := append(mapKeys(categories), mapKeys(categoryAliases)...)
+		errs = append(errs, fmt.Errorf("%s: %w", name, unknownError("fail_on category", c, candidates)))
+	}
+	return errs
+}
+
+// policy is a compiled ErrorPolicy.
+type policy struct {
+	action Action
+	failOn map[Category]bool
+}
+
+// newPolicy compiles a validated policy.
+func newPolicy(p ErrorPolicy) policy {
+	compiled := policy{
+		action: Action(p.Action),
+		failOn: map[Category]bool{},
+	}
+	for _, c := range p.FailOn {
+		for _, a := range categoryAliases[c] {
+			compiled.failOn[a] = true
+		}
+		if categories[Category(c)] {
+			compiled.failOn[Category(c)] = true
+		}
+	}
+	return compiled
+}
+
+// validatePolicies checks the error_policy section for the signals.
+func (c Config) validatePolicies(signals ...string) []error {
+	return c.ErrorPolicy.validate("error_policy", signals...)
+}
+
+// validate checks the policy and the overrides of the signals, prefixing
+// errors with name.
+func (p ErrorPolicies) validate(name string, signals ...string) []error {
+	errs := p.ErrorPolicy.validate(name)
+	for _, signal := range signals {
+		errs = append(errs, p.forSignal(signal).validate(name+"."+signal)...)
+	}
+	return errs
+}
+
+// failures are the findings that the policy fails on. Advisory findings never
+// fail.
+func (p policy) failures(findings []Finding) []Finding {
+	failed := []Finding{}
+	for _, f := range findings {
+		if p.failOn[f.Category] && !f.Advisory {
+			failed = append(failed, f)
+		}
+	}
+	return failed
+}
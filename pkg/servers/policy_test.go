@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignalPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		signal string
+		want   ErrorPolicy
+	}{
+		{
+			name:   "default",
+			signal: "trace",
+			want:   defaultPolicy,
+		},
+		{
+			name:   "disable error",
+			cfg:    Config{DisableError: true},
+			signal: "trace",
+			want:   ErrorPolicy{Action: "accept", FailOn: defaultPolicy.FailOn},
+		},
+		{
+			name: "signal overrides global",
+			cfg: Config{ErrorPolicy: ErrorPolicies{
+				ErrorPolicy: ErrorPolicy{Action: "reject", FailOn: []string{"required-missing"}},
+				Metrics:     ErrorPolicy{Action: "partial_success"},
+			}},
+			signal: "metrics",
+			want:   ErrorPolicy{Action: "partial_success", FailOn: []string{"required-missing"}},
+		},
+		{
+			name: "other signals keep global",
+			cfg: Config{ErrorPolicy: ErrorPolicies{
+				ErrorPolicy: ErrorPolicy{Action: "accept"},
+				Metrics:     ErrorPolicy{Action: "partial_success"},
+			}},
+			signal: "log",
+			want:   ErrorPolicy{Action: "accept", FailOn: defaultPolicy.FailOn},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.cfg.signalPolicy(tt.signal))
+		})
+	}
+}
+
+func TestErrorPolicyValidate(t *testing.T) {
+	assert.Empty(t, ErrorPolicy{Action: "reject", FailOn: []string{"missing", "extra"}}.validate("p"))
+
+	errs := ErrorPolicy{Action: "drop", FailOn: []string{"required_missing"}}.validate("p")
+	if assert.Len(t, errs, 2) {
+		assert.EqualError(t, errs[0], `p: unknown action "drop"`)
+		assert.EqualError(t, errs[1], `p: unknown fail_on category "required_missing", did you mean "required-missing"?`)
+	}
+}
+
+func TestPolicyFailures(t *testing.T) {
+	p := newPolicy(ErrorPolicy{Action: "reject", FailOn: []string{"required-missing", "type-mismatch"}})
+	findings := []Finding{
+		{Attribute: "http.route", Category: ConditionallyRequiredMissing},
+		{Attribute: "url.scheme", Category: RequiredMissing},
+		{Attribute: "server.port", Category: TypeMismatch},
+		{Attribute: "http.method", Category: Extra},
+		{Attribute: "db.system", Category: RequiredMissing, Stability: "experimental", Advisory: true},
+	}
+
+	assert.Equal(t, []Finding{findings[1], findings[2]}, p.failures(findings))
+}
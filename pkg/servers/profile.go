@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ProfileHeader selects the profile used to check a request.
+	ProfileHeader = "x-semconv-profile"
+	// SessionHeader names the session the items of a request belong to, so
+	// observers can keep their results apart.
+	SessionHeader = "x-semconv-session"
+)
+
+// requestHeaders returns the profile and session of the request.
+func requestHeaders(ctx context.Context) (profile, session string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(ProfileHeader); len(v) > 0 {
+		profile = v[0]
+	}
+	if v := md.Get(SessionHeader); len(v) > 0 {
+		session = v[0]
+	}
+	return profile, session
+}
+
+func (p Profile) forSignal(signal string) []Match {
+	switch signal {
+	case "trace":
+		return p.Trace
+	case "metrics":
+		return p.Metrics
+	case "log":
+		return p.Log
+	}
+	return nil
+}
+
+// withProfile applies the profile to the config.
+func (c Config) withProfile(p Profile) Config {
+	c.Profiles = nil
+	c.Ignore = append(slices.Clone(c.Ignore), p.Ignore...)
+	if p.Trace != nil {
+		c.Trace = p.Trace
+	}
+	if p.Metrics != nil {
+		c.Metrics = p.Metrics
+	}
+	if p.Log != nil {
+		c.Log = p.Log
+	}
+	if p.SemanticVersion != "" {
+		c.Trace = withVersion(p.SemanticVersion, c.Trace)
+		c.Metrics = withVersion(p.SemanticVersion, c.Metrics)
+		c.Log = withVersion(p.SemanticVersion, c.Log)
+	}
+	c.ErrorPolicy = ErrorPolicies{
+		ErrorPolicy: c.ErrorPolicy.ErrorPolicy.merge(p.ErrorPolicy.ErrorPolicy),
+		Trace:       c.ErrorPolicy.Trace.merge(p.ErrorPolicy.Trace),
+		Metrics:     c.ErrorPolicy.Metrics.merge(p.ErrorPolicy.Metrics),
+		Log:         c.ErrorPolicy.Log.merge(p.ErrorPolicy.Log),
+	}
+	return c
+}
+
+// forProfile returns the config of the profile, the empty profile is the
+// config itself.
+func (c *signalConfig) forProfile(name string) (*signalConfig, error) {
+	if name == "" {
+		return c, nil
+	}
+	if p, ok := c.profiles[name]; ok {
+		return p, nil
+	}
+	return nil, status.Error(codes.InvalidArgument, unknownError("profile", name, mapKeys(c.profiles)).Error())
+}
+
+func profileNames(profiles map[string]Profile) []string {
+	names := mapKeys(profiles)
+	sort.Strings(names)
+	return names
+}
+
+func profileError(name string, err error) error {
+	return fmt.Errorf("profiles.%s: %w", name, err)
+}
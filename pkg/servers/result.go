@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// summaryLimit is the number of groups, and attributes per group, in the
+// result message.
+const summaryLimit = 3
+
+// Finding is an attribute that doesn't follow the semantic convention.
+type Finding struct {
+	// Group is the semantic convention group the attribute is from. It is
+	// empty for extra attributes.
+	Group     string
+	Attribute string
+	Category  Category
+	// Suggestion is the registry attribute a lint finding is likely a typo
+	// of.
+	Suggestion string
+	// Stability is the stability of the attribute in its group, e.g. stable
+	// or experimental. It is empty for attributes that aren't from a group.
+	Stability string
+	// Advisory is set for findings of attributes that aren't stable when the
+	// match warns about them, they don't fail the error policy.
+	Advisory bool
+}
+
+// Defined reports if the attribute of the finding is defined by a group or
+// the config. The names of extra, unknown and high cardinality attributes
+// that aren't from a group come from the telemetry.
+func (f Finding) Defined() bool {
+	switch f.Group {
+	case "", lintGroup, cardinalityGroup:
+		return false
+	}
+	return true
+}
+
+// result collects the outcome of the items, spans, data points or log
+// records, in an Export request.
+type result struct {
+	// unit names an item in the message, e.g. span.
+	unit   string
+	action Action
+	// rejected holds the items that failed their policy.
+	rejected map[any]bool
+	// counts is the number of failed findings by group and attribute.
+	counts map[string]map[string]int
+}
+
+func newResult(unit string) *result {
+	return &result{
+		unit:     unit,
+		rejected: map[any]bool{},
+		counts:   map[string]map[string]int{},
+	}
+}
+
+// add records the findings of a match for an item. An item is only counted
+// once, even if several matches or findings fail it.
+func (r *result) add(item any, p policy, findings []Finding) {
+	failed := p.failures(findings)
+	if len(failed) == 0 || p.action == Accept {
+		return
+	}
+	r.rejected[item] = true
+	for _, f := range failed {
+		group := f.Group
+		if group == "" {
+			group = string(f.Category)
+		}
+		if r.counts[group] == nil {
+			r.counts[group] = map[string]int{}
+		}
+		r.counts[group][f.Attribute]++
+	}
+	if p.action.severity() > r.action.severity() {
+		r.action = p.action
+	}
+}
+
+// partialSuccess returns the number of rejected items and the message for the
+// response. ok is false if nothing was rejected.
+func (r *result) partialSuccess() (rejected int64, msg string, ok bool) {
+	if len(r.rejected) == 0 {
+		return 0, "", false
+	}
+	return int64(len(r.rejected)), r.message(), true
+}
+
+// err is the error returned by Export, only the reject action returns one.
+func (r *result) err() error {
+	if r.action != Reject {
+		return nil
+	}
+	return status.Error(codes.FailedPrecondition, r.message())
+}
+
+// message summarises the groups and attributes with the most findings, e.g.
+// "2 spans rejected: trace.http.server: url.scheme (2), http.route (1)".
+func (r *result) message() string {
+	type count struct {
+		name  string
+		total int
+	}
+	sorted := func(m map[string]int) []count {
+		counts := []count{}
+		for name, total := range m {
+			counts = append(counts, count{name, total})
+		}
+		sort.Slice(counts, func(i, j int) bool {
+			if counts[i].total != counts[j].total {
+				return counts[i].total > counts[j].total
+			}
+			return counts[i].name < counts[j].name
+		})
+		return counts
+	}
+
+	groupTotals := map[string]int{}
+	for group, attrs := range r.counts {
+		for _, n := range attrs {
+			groupTotals[group] += n
+		}
+	}
+
+	groups := []string{}
+	for i, g := range sorted(groupTotals) {
+		if i == summaryLimit {
+			groups = append(groups, fmt.Sprintf("and %d more groups", len(groupTotals)-summaryLimit))
+			break
+		}
+		attrs := []string{}
+		for j, a := range sorted(r.counts[g.name]) {
+			if j == summaryLimit {
+				attrs = append(attrs, "...")
+				break
+			}
+			attrs = append(attrs, fmt.Sprintf("%s (%d)", a.name, a.total))
+		}
+		groups = append(groups, fmt.Sprintf("%s: %s", g.name, strings.Join(attrs, ", ")))
+	}
+	unit := r.unit
+	if len(r.rejected) != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s rejected: %s", len(r.rejected), unit, strings.Join(groups, "; "))
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultAction(t *testing.T) {
+	partial := newPolicy(ErrorPolicy{Action: "partial_success", FailOn: []string{"missing"}})
+	reject := newPolicy(ErrorPolicy{Action: "reject", FailOn: []string{"missing"}})
+	accept := newPolicy(ErrorPolicy{Action: "accept", FailOn: []string{"missing"}})
+	missing := []Finding{{Group: "trace.http.server", Attribute: "url.scheme", Category: RequiredMissing}}
+
+	res := newResult("span")
+	res.add("accepted", accept, missing)
+	_, _, ok := res.partialSuccess()
+	assert.False(t, ok)
+
+	res.add("partial", partial, missing)
+	_, _, ok = res.partialSuccess()
+	assert.True(t, ok)
+	assert.NoError(t, res.err())
+
+	res.add("rejected", reject, missing)
+	assert.ErrorContains(t, res.err(), "2 spans rejected: trace.http.server: url.scheme (2)")
+}
+
+func TestResultCountsItems(t *testing.T) {
+	p := newPolicy(defaultPolicy)
+	res := newResult("span")
+
+	// One span missing several attributes, and failing two matches, is one rejected span.
+	res.add("span-1", p, []Finding{
+		{Group: "trace.http.server", Attribute: "url.scheme", Category: RequiredMissing},
+		{Group: "trace.http.server", Attribute: "url.path", Category: RequiredMissing},
+		{Group: "trace.http.server", Attribute: "http.route", Category: ConditionallyRequiredMissing},
+	})
+	res.add("span-1", p, []Finding{
+		{Group: "trace.http.common", Attribute: "http.request.method", Category: RequiredMissing},
+	})
+	res.add("span-2", p, []Finding{
+		{Group: "trace.http.server", Attribute: "url.scheme", Category: RequiredMissing},
+		{Attribute: "http.method", Category: Extra},
+	})
+	res.add("span-3", p, []Finding{
+		{Attribute: "http.method", Category: Extra},
+	})
+
+	rejected, msg, ok := res.partialSuccess()
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), rejected)
+	assert.Equal(t, "2 spans rejected: trace.http.server: url.scheme (2), http.route (1), url.path (1); trace.http.common: http.request.method (1)", msg)
+}
+
+func TestResultMessageLimit(t *testing.T) {
+	p := newPolicy(defaultPolicy)
+	res := newResult("log record")
+	res.add("record", p, []Finding{
+		{Group: "a", Attribute: "a.1", Category: RequiredMissing},
+		{Group: "a", Attribute: "a.2", Category: RequiredMissing},
+		{Group: "a", Attribute: "a.3", Category: RequiredMissing},
+		{Group: "a", Attribute: "a.4", Category: RequiredMissing},
+		{Group: "b", Attribute: "b.1", Category: RequiredMissing},
+		{Group: "c", Attribute: "c.1", Category: RequiredMissing},
+		{Group: "d", Attribute: "d.1", Category: RequiredMissing},
+	})
+
+	assert.Equal(t, "1 log record rejected: a: a.1 (1), a.2 (1), a.3 (1), ...; b: b.1 (1); c: c.1 (1); and 1 more groups", res.message())
+}
+
+func TestFindingDefined(t *testing.T) {
+	assert.True(t, Finding{Group: "trace.http.server", Attribute: "url.scheme"}.Defined())
+	assert.True(t, Finding{Group: includeGroup, Attribute: "app.id"}.Defined())
+	assert.False(t, Finding{Attribute: "app.id", Category: Extra}.Defined())
+	assert.False(t, Finding{Group: lintGroup, Attribute: "app.ID", Category: Naming}.Defined())
+	assert.False(t, Finding{Group: cardinalityGroup, Attribute: "app.id", Category: HighCardinality}.Defined())
+}
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+// serviceMatches are the matches used for the resources a service selects.
+type serviceMatches struct {
+	selector map[string]string
+	matches  []matchDef
+}
+
+func (c Config) forSignal(signal string) []Match {
+	switch signal {
+	case "trace":
+		return c.Trace
+	case "metrics":
+		return c.Metrics
+	case "log":
+		return c.Log
+	}
+	return nil
+}
+
+func (s Service) forSignal(signal string) []Match {
+	switch signal {
+	case "trace":
+		return s.Trace
+	case "metrics":
+		return s.Metrics
+	case "log":
+		return s.Log
+	}
+	return nil
+}
+
+// withVersion sets the semantic version of the service on the matches that
+// don't set one.
+func (s Service) withVersion(matches []Match) []Match {
+	return withVersion(s.SemanticVersion, matches)
+}
+
+func withVersion(version string, matches []Match) []Match {
+	if matches == nil {
+		return nil
+	}
+	out := slices.Clone(matches)
+	for i := range out {
+		if out[i].SemanticVersion == "" {
+			out[i].SemanticVersion = version
+		}
+	}
+	return out
+}
+
+// newServiceSelector compiles the selector and ignore list of a service,
+// shared by every signal.
+func newServiceSelector(section string, s Service, svs map[string]semconv.SemanticVersion) (map[string]string, []pattern, []error) {
+	errs := []error{}
+	selector := map[string]string{}
+	if s.Name != "" {
+		selector["service.name"] = s.Name
+	}
+	for _, attr := range s.ResourceAttributes {
+		if attr.Name == "" {
+			errs = append(errs, fmt.Errorf("%s: resource_attributes: empty name", section))
+		}
+		selector[attr.Name] = attr.Value
+	}
+	if len(selector) == 0 {
+		errs = append(errs, fmt.Errorf("%s: name or resource_attributes is required", section))
+	}
+	if _, ok := svs[s.SemanticVersion]; s.SemanticVersion != "" && !ok {
+		errs = append(errs, fmt.Errorf("%s: %w", section, unknownError("semantic_version", s.SemanticVersion, mapKeys(svs))))
+	}
+	ignore, perrs := sharedPatterns(section, section+": ignore", s.Ignore)
+	return selector, ignore, append(errs, perrs...)
+}
+
+// newServiceMatches compiles the matches of a signal for the service: the
+// global matches followed by its own, with its semantic version and ignore
+// list. The global matches keep their section, so ignore usage is counted
+// with the global config.
+func newServiceMatches(i int, s Service, signal string, global []Match, globalIgnore []pattern, signalPolicy ErrorPolicy, svs map[string]semconv.SemanticVersion) (serviceMatches, error) {
+	section := fmt.Sprintf("services[%d]", i)
+	selector, ignore, errs := newServiceSelector(section, s, svs)
+	if err := errors.Join(errs...); err != nil {
+		return serviceMatches{}, err
+	}
+	shared := append(slices.Clone(globalIgnore), ignore...)
+
+	globalDefs, err := newMatchDefs(signal, s.withVersion(global), shared, signalPolicy, svs)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", section, err))
+	}
+	defs, err := newMatchDefs(section+"."+signal, s.withVersion(s.forSignal(signal)), shared, signalPolicy, svs)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return serviceMatches{}, err
+	}
+	return serviceMatches{
+		selector: selector,
+		matches:  append(globalDefs, defs...),
+	}, nil
+}
+
+// matchesFor returns the matches for a resource, those of the first service
+// that selects it or the global matches.
+func (c *signalConfig) matchesFor(resource []*v1.KeyValue) []matchDef {
+	for _, s := range c.services {
+		if attrsMatch(s.selector, resource) {
+			return s.matches
+		}
+	}
+	return c.matches
+}
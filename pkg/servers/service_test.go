@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+func TestSignalConfigMatchesFor(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	cfg := Config{
+		Ignore: []string{"process.*"},
+		Trace:  []Match{{Include: []string{"app.id"}}},
+		Services: []Service{
+			{
+				Name:            "legacy",
+				SemanticVersion: "https://opentelemetry.io/schemas/1.20.0",
+				Ignore:          []string{"app.id"},
+				Trace:           []Match{{Include: []string{"legacy.id"}}},
+			},
+			{
+				ResourceAttributes: []Attribute{{Name: "deployment.environment", Value: "dev"}},
+			},
+		},
+	}
+	c, err := newSignalConfig(cfg, "trace", svs)
+	require.NoError(t, err)
+
+	global := c.matchesFor([]*v1.KeyValue{createKeyValue("service.name", "api")})
+	require.Len(t, global, 1)
+	assert.Equal(t, semconv.DefaultVersion, *global[0].semVer)
+
+	legacy := c.matchesFor([]*v1.KeyValue{createKeyValue("service.name", "legacy")})
+	require.Len(t, legacy, 2)
+	assert.Equal(t, "trace[0]", legacy[0].section)
+	assert.Equal(t, "https://opentelemetry.io/schemas/1.20.0", *legacy[0].semVer)
+	assert.Equal(t, "services[0].trace[0]", legacy[1].section)
+	assert.Empty(t, legacy[0].filter([]string{"app.id", "process.pid"}), "global and service ignore both apply")
+
+	dev := c.matchesFor([]*v1.KeyValue{
+		createKeyValue("service.name", "api"),
+		createKeyValue("deployment.environment", "dev"),
+	})
+	require.Len(t, dev, 1)
+	assert.NotSame(t, &global[0], &dev[0])
+
+	assert.Contains(t, c.ignoreUsage(), IgnoreUsage{Section: "services[0]", Pattern: "app.id", Hits: 1})
+}
+
+func TestConfigValidateServices(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	cfg := Config{
+		ServerAddress: "localhost:4317",
+		Trace:         []Match{{Groups: []string{"trace.http.server"}}},
+		Services: []Service{
+			{Ignore: []string{""}},
+			{Name: "api", SemanticVersion: "1.0.0"},
+			{Name: "web", Log: []Match{{Groups: []string{"nope"}}}},
+		},
+	}
+	err = cfg.Validate(svs)
+	require.Error(t, err)
+	for _, want := range []string{
+		"services[0]: name or resource_attributes is required",
+		"services[0]: ignore: empty entry",
+		`services[1]: unknown semantic_version "1.0.0"`,
+		`services[2].log[0]: unknown group "nope"`,
+	} {
+		assert.ErrorContains(t, err, want)
+	}
+}
@@ -0,0 +1,200 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+// spanNameAttribute is the attribute of findings about the span name, so
+// they can be ignored like an attribute.
+const spanNameAttribute = "span.name"
+
+// spanNameGroup is the group of span name findings for a match with
+// span_name templates.
+const spanNameGroup = "span_name"
+
+// spanNameRules are the span name conventions of the span groups. The first
+// template with all its attributes on the span is the expected name.
+var spanNameRules = []spanName{
+	mustSpanName("trace.http.server", "{http.request.method|http.method} {http.route}", "{http.request.method|http.method}").withValues("http.request.method", httpMethodNames),
+	mustSpanName("trace.http.client", "{http.request.method|http.method}").withValues("http.request.method", httpMethodNames),
+	mustSpanName("db", "{db.operation} {db.name}.{db.sql.table}", "{db.operation} {db.name}", "{db.operation}", "{db.name}", "{db.system}"),
+	mustSpanName("rpc", "{rpc.service}/{rpc.method}"),
+	mustSpanName("messaging", "{messaging.destination.name} {messaging.operation}"),
+}
+
+// httpMethodNames are the names of the methods the conventions don't know,
+// which are recorded as _OTHER.
+var httpMethodNames = map[string]string{"_OTHER": "HTTP"}
+
+// spanName is the span name convention of a match.
+type spanName struct {
+	group     string
+	templates []nameTemplate
+	// values replace the values of an attribute in the name.
+	values map[string]map[string]string
+}
+
+func mustSpanName(group string, templates ...string) spanName {
+	sn := spanName{group: group}
+	for _, raw := range templates {
+		t, err := newNameTemplate(raw)
+		if err != nil {
+			panic(err)
+		}
+		sn.templates = append(sn.templates, t)
+	}
+	return sn
+}
+
+// withValues replaces the values of the attribute in the name.
+func (sn spanName) withValues(attr string, values map[string]string) spanName {
+	if sn.values == nil {
+		sn.values = map[string]map[string]string{}
+	}
+	sn.values[attr] = values
+	return sn
+}
+
+// nameTemplate is a span name with placeholders, e.g. {http.route}. A
+// placeholder can list alternatives for older versions, e.g.
+// {http.request.method|http.method}.
+type nameTemplate struct {
+	parts []namePart
+}
+
+type namePart struct {
+	literal string
+	attrs   []string
+}
+
+func newNameTemplate(raw string) (nameTemplate, error) {
+	t := nameTemplate{}
+	rest := raw
+	for rest != "" {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			t.parts = append(t.parts, namePart{literal: rest})
+			break
+		}
+		if start > 0 {
+			t.parts = append(t.parts, namePart{literal: rest[:start]})
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return t, fmt.Errorf("unclosed placeholder in %q", raw)
+		}
+		attrs := strings.Split(rest[start+1:start+end], "|")
+		for _, a := range attrs {
+			if a == "" {
+				return t, fmt.Errorf("empty placeholder in %q", raw)
+			}
+		}
+		t.parts = append(t.parts, namePart{attrs: attrs})
+		rest = rest[start+end+1:]
+	}
+	return t, nil
+}
+
+// render returns the name with the attributes, their values replaced by
+// values, false if an attribute of a placeholder is missing.
+func (t nameTemplate) render(attrs []*v1.KeyValue, values map[string]map[string]string) (string, bool) {
+	b := strings.Builder{}
+	for _, p := range t.parts {
+		if p.attrs == nil {
+			b.WriteString(p.literal)
+			continue
+		}
+		found := false
+		for _, a := range p.attrs {
+			if s, ok := valueString(findValue(a, attrs)); ok {
+				if r, ok := values[a][s]; ok {
+					s = r
+				}
+				b.WriteString(s)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+// newSpanName compiles the span_name templates of a match, or the rule of
+// its first group that has one. It is nil when there is no convention.
+func newSpanName(section string, m Match) (*spanName, []error) {
+	if len(m.SpanName) > 0 {
+		sn := &spanName{group: spanNameGroup}
+		errs := []error{}
+		for _, raw := range m.SpanName {
+			t, err := newNameTemplate(raw)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: span_name: %w", section, err))
+				continue
+			}
+			sn.templates = append(sn.templates, t)
+		}
+		return sn, errs
+	}
+	for _, group := range m.Groups {
+		for _, rule := range spanNameRules {
+			if group != rule.group && !strings.HasPrefix(group, rule.group+".") {
+				continue
+			}
+			return &spanName{group: group, templates: rule.templates, values: rule.values}, nil
+		}
+	}
+	return nil, nil
+}
+
+// expected returns the name the convention expects for the attributes, false
+// when no template has its attributes.
+func (sn *spanName) expected(attrs []*v1.KeyValue) (string, bool) {
+	if sn == nil {
+		return "", false
+	}
+	for _, t := range sn.templates {
+		if name, ok := t.render(attrs, sn.values); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// idInName finds what makes a span name unique per request: full URLs, query
+// strings, UUIDs, long hex ids and numeric path segments.
+var idInName = regexp.MustCompile(`://|\?|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|\b[0-9a-fA-F]{16,}\b|/\d+(/|\s|$)`)
+
+// hasIDInName reports if the span name looks like it has a value per
+// request.
+func hasIDInName(name string) bool {
+	return idInName.MatchString(name)
+}
+
+// spanNameFindings returns the findings for the span name, and the expected
+// name when it doesn't follow the convention. highCardinality is if the name
+// has an id or its scope has too many names.
+func (m matchDef) spanNameFindings(name string, attrs []*v1.KeyValue, highCardinality bool) ([]Finding, string) {
+	findings := []Finding{}
+	if highCardinality {
+		findings = append(findings, Finding{Group: cardinalityGroup, Attribute: spanNameAttribute, Category: HighCardinality})
+	}
+	want, ok := m.spanName.expected(attrs)
+	if ok && want != name {
+		findings = append(findings, Finding{Group: m.spanName.group, Attribute: spanNameAttribute, Category: SpanNameMismatch})
+	} else {
+		want = ""
+	}
+	if len(findings) == 0 || len(m.filter([]string{spanNameAttribute})) == 0 {
+		return []Finding{}, ""
+	}
+	return findings, want
+}
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+func TestSpanNameExpected(t *testing.T) {
+	server, errs := newSpanName("trace[0]", Match{Groups: []string{"trace.http.server"}})
+	require.Empty(t, errs)
+
+	name, ok := server.expected([]*v1.KeyValue{
+		createKeyValue("http.request.method", "GET"),
+		createKeyValue("http.route", "/users/{id}"),
+	})
+	assert.True(t, ok)
+	assert.Equal(t, "GET /users/{id}", name)
+
+	name, ok = server.expected([]*v1.KeyValue{createKeyValue("http.method", "POST")})
+	assert.True(t, ok, "falls back to the method, from an older version")
+	assert.Equal(t, "POST", name)
+
+	name, ok = server.expected([]*v1.KeyValue{
+		createKeyValue("http.request.method", "_OTHER"),
+		createKeyValue("http.route", "/users/{id}"),
+	})
+	assert.True(t, ok)
+	assert.Equal(t, "HTTP /users/{id}", name, "an unknown method is named HTTP")
+
+	client, errs := newSpanName("trace[0]", Match{Groups: []string{"trace.http.client"}})
+	require.Empty(t, errs)
+	name, _ = client.expected([]*v1.KeyValue{createKeyValue("http.request.method", "_OTHER")})
+	assert.Equal(t, "HTTP", name)
+
+	_, ok = server.expected(nil)
+	assert.False(t, ok)
+
+	db, errs := newSpanName("trace[0]", Match{Groups: []string{"db.sql"}})
+	require.Empty(t, errs)
+	name, _ = db.expected([]*v1.KeyValue{
+		createKeyValue("db.operation", "SELECT"),
+		createKeyValue("db.name", "shop"),
+		createKeyValue("db.sql.table", "orders"),
+	})
+	assert.Equal(t, "SELECT shop.orders", name)
+
+	none, errs := newSpanName("trace[0]", Match{Groups: []string{"code"}})
+	assert.Empty(t, errs)
+	assert.Nil(t, none)
+
+	custom, errs := newSpanName("trace[0]", Match{Groups: []string{"trace.http.server"}, SpanName: []string{"{app.job}"}})
+	require.Empty(t, errs)
+	name, _ = custom.expected([]*v1.KeyValue{createKeyValue("app.job", "cleanup")})
+	assert.Equal(t, "cleanup", name)
+	assert.Equal(t, spanNameGroup, custom.group)
+}
+
+func TestHasIDInName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"GET /users/{id}": false,
+		"GET /v1/users":   false,
+		"SELECT shop":     false,
+		"GET /users/42":   true,
+		"GET /users/42/x": true,
+		"GET https://a/b": true,
+		"GET /search?q=a": true,
+		"process 4bf92f3577b34da6a3ce929d0e0e4736": true,
+		"job 123e4567-e89b-12d3-a456-426614174000": true,
+	} {
+		assert.Equal(t, want, hasIDInName(name), name)
+	}
+}
+
+func TestSpanNameFindingsIgnored(t *testing.T) {
+	m := newTestMatchDef(nil, []string{"span.name"})
+	m.spanName, _ = newSpanName("trace[0]", Match{Groups: []string{"trace.http.client"}})
+
+	findings, want := m.spanNameFindings("GET /users/42", []*v1.KeyValue{createKeyValue("http.request.method", "GET")}, true)
+	assert.Empty(t, findings)
+	assert.Empty(t, want)
+}
+
+func TestTraceServerSpanNames(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	rec := &itemRecorder{}
+	s, err := NewTraceService(Config{
+		Trace: []Match{{
+			Groups:      []string{"trace.http.server"},
+			ErrorPolicy: ErrorPolicy{Action: string(Accept)},
+		}},
+		Cardinality: Cardinality{SpanNameLimit: 10},
+	}, svs, rec)
+	require.NoError(t, err)
+
+	// The span of newRequest is named test.
+	_, err = s.Export(context.Background(), newRequest([]attribute.KeyValue{
+		attribute.String("http.request.method", "GET"),
+		attribute.String("http.route", "/users/{id}"),
+	}, nil, nil))
+	require.NoError(t, err)
+
+	req := newRequest([]attribute.KeyValue{attribute.String("http.request.method", "GET")}, nil, nil)
+	req.ResourceSpans[0].ScopeSpans[0].Spans[0].Name = "GET /users/42"
+	_, err = s.Export(context.Background(), req)
+	require.NoError(t, err)
+
+	nameFindings := func(item Item) []Finding {
+		found := []Finding{}
+		for _, f := range item.Findings {
+			if f.Attribute == spanNameAttribute {
+				found = append(found, f)
+			}
+		}
+		return found
+	}
+	require.Len(t, rec.items, 2)
+	assert.Equal(t, []Finding{
+		{Group: "trace.http.server", Attribute: spanNameAttribute, Category: SpanNameMismatch},
+	}, nameFindings(rec.items[0]))
+	assert.Equal(t, []Finding{
+		{Group: cardinalityGroup, Attribute: spanNameAttribute, Category: HighCardinality},
+		{Group: "trace.http.server", Attribute: spanNameAttribute, Category: SpanNameMismatch},
+	}, nameFindings(rec.items[1]))
+}
@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+)
+
+// The experimental modes of a match, for the attributes of its groups that
+// aren't stable.
+const (
+	// experimentalCheck checks them like stable attributes.
+	experimentalCheck = "check"
+	// experimentalWarn reports their findings as advisory, they don't fail
+	// the error policy.
+	experimentalWarn = "warn"
+	// experimentalExclude doesn't check them.
+	experimentalExclude = "exclude"
+)
+
+var experimentalModes = []string{experimentalCheck, experimentalWarn, experimentalExclude}
+
+// newExperimental validates the experimental mode of a match, check when it
+// isn't set.
+func newExperimental(section, mode string) (string, error) {
+	if mode == "" {
+		return experimentalCheck, nil
+	}
+	if !slices.Contains(experimentalModes, mode) {
+		return "", fmt.Errorf("%s: %w", section, unknownError("experimental", mode, slices.Clone(experimentalModes)))
+	}
+	return mode, nil
+}
+
+// finding returns the finding for an attribute of the match, with the
+// stability of the attribute in its group. Attributes that aren't from a
+// group have no stability, and are never advisory.
+func (m matchDef) finding(group, name string, c Category) Finding {
+	stability := m.stability[name]
+	return Finding{
+		Group:     group,
+		Attribute: name,
+		Category:  c,
+		Stability: stability,
+		Advisory:  m.experimental == experimentalWarn && stability != "" && stability != semconv.Stable,
+	}
+}
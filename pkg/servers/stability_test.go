@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+func findingFor(findings []Finding, name string) (Finding, bool) {
+	for _, f := range findings {
+		if f.Attribute == name {
+			return f, true
+		}
+	}
+	return Finding{}, false
+}
+
+func TestExperimentalWarn(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	m, err := newMatchDef("trace[0]", Match{
+		SemanticVersion: "https://opentelemetry.io/schemas/1.24.0",
+		Groups:          []string{"db"},
+		Experimental:    experimentalWarn,
+	}, nil, defaultPolicy, svs)
+	require.NoError(t, err)
+
+	findings := m.compareAttributes(nil)
+	system, ok := findingFor(findings, "db.system")
+	require.True(t, ok)
+	assert.Equal(t, Finding{Group: "db", Attribute: "db.system", Category: RequiredMissing, Stability: semconv.Experimental, Advisory: true}, system)
+	address, ok := findingFor(findings, "server.address")
+	require.True(t, ok)
+	assert.Equal(t, semconv.Stable, address.Stability)
+	assert.False(t, address.Advisory)
+
+	_, ok = findingFor(m.policy.failures(findings), "db.system")
+	assert.False(t, ok, "advisory findings don't fail")
+}
+
+func TestExperimentalExclude(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	m, err := newMatchDef("trace[0]", Match{
+		SemanticVersion:  "https://opentelemetry.io/schemas/1.24.0",
+		Groups:           []string{"db"},
+		Experimental:     experimentalExclude,
+		ReportAdditional: true,
+	}, nil, defaultPolicy, svs)
+	require.NoError(t, err)
+
+	findings := m.compareAttributes([]*v1.KeyValue{createKeyValue("db.system", "postgresql")})
+	_, ok := findingFor(findings, "db.system")
+	assert.False(t, ok, "excluded attributes are neither checked nor extra")
+	_, ok = findingFor(findings, "server.address")
+	assert.True(t, ok)
+	for _, e := range m.expected {
+		assert.Equal(t, semconv.Stable, m.stability[e.Attribute], e.Attribute)
+	}
+}
+
+func TestExperimentalCheck(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	m, err := newMatchDef("trace[0]", Match{
+		SemanticVersion: "https://opentelemetry.io/schemas/1.24.0",
+		Groups:          []string{"db"},
+	}, nil, defaultPolicy, svs)
+	require.NoError(t, err)
+
+	system, ok := findingFor(m.compareAttributes(nil), "db.system")
+	require.True(t, ok)
+	assert.Equal(t, semconv.Experimental, system.Stability)
+	assert.False(t, system.Advisory)
+}
@@ -4,79 +4,74 @@ package servers
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"sync/atomic"
 
 	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
 	pbCollectorTrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	pbTrace "go.opentelemetry.io/proto/otlp/trace/v1"
 )
 
 type TraceServer struct {
 	pbCollectorTrace.UnimplementedTraceServiceServer
 
-	resource        matchDef
-	matches         []matchDef
-	reportUnmatched bool
-
-	disableError bool
+	config      atomic.Pointer[signalConfig]
+	observers   observers
+	cardinality *cardinalityTracker
 }
 
-func NewTraceService(cfg Config, svs map[string]semconv.SemanticVersion) *TraceServer {
-	_, found := svs[cfg.Resource.SemanticVersion]
-	if cfg.Resource.SemanticVersion != "" && !found {
-		cfg.Resource.SemanticVersion = semconv.DefaultVersion
+func NewTraceService(cfg Config, svs map[string]semconv.SemanticVersion, obs ...Observer) (*TraceServer, error) {
+	s := &TraceServer{observers: obs, cardinality: newCardinalityTracker()}
+	if err := s.Update(cfg, svs); err != nil {
+		return nil, err
 	}
+	return s, nil
+}
 
-	resSemVer := cfg.Resource.SemanticVersion
-	if !found {
-		resSemVer = semconv.DefaultVersion
-	}
-	resource := newMatchDef(cfg.Resource, svs[resSemVer].Groups)
-
-	matches := []matchDef{}
-	for _, match := range cfg.Trace {
-		groups, ok := svs[match.SemanticVersion]
-		if !ok {
-			match.SemanticVersion = semconv.DefaultVersion
-			groups = svs[match.SemanticVersion]
-		}
-		matches = append(matches, newMatchDef(match, groups.Groups))
+// Update replaces the config of the server. If the new config is invalid the
+// previous config is kept. The hits of ignore entries carry over to the same
+// entries of the new config.
+func (s *TraceServer) Update(cfg Config, svs map[string]semconv.SemanticVersion) error {
+	c, err := newSignalConfig(cfg, "trace", svs)
+	if err != nil {
+		return err
 	}
+	c.carryIgnoreHits(s.config.Swap(c))
+	return nil
+}
 
-	return &TraceServer{
-		resource:        resource,
-		matches:         matches,
-		reportUnmatched: cfg.ReportUnmatched,
-		disableError:    cfg.DisableError,
-	}
+// IgnoreUsage reports how many attributes each ignore entry has matched.
+func (s *TraceServer) IgnoreUsage() []IgnoreUsage {
+	return s.config.Load().ignoreUsage()
+}
+
+// FixedBaseline returns the baseline entries that were checked and no longer
+// found.
+func (s *TraceServer) FixedBaseline() []BaselineEntry {
+	return s.config.Load().baseline.fixed()
 }
 
 func (s *TraceServer) Export(ctx context.Context, req *pbCollectorTrace.ExportTraceServiceRequest) (*pbCollectorTrace.ExportTraceServiceResponse, error) {
 	if req == nil {
 		return nil, nil
 	}
-	count := 0
-	names := []string{}
+	profile, session := requestHeaders(ctx)
+	cfg, err := s.config.Load().forProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	res := newResult("span")
 	for _, r := range req.ResourceSpans {
 		log := slog.With("type", "trace")
 		if schema := r.GetSchemaUrl(); schema != "" {
 			log = log.With("resource.schema", schema)
 		}
-		if attr := r.Resource.GetAttributes(); len(attr) > 0 {
-			name := ""
-			for _, kv := range attr {
-				if kv.Key == "service.name" {
-					name = kv.Value.GetStringValue()
-				}
-				if name == "" {
-					name = kv.String()
-				}
-			}
-			if name != "" {
-				log = log.With("service.name", name)
-			}
+		service := serviceName(r.GetResource().GetAttributes())
+		matches := cfg.matchesFor(r.GetResource().GetAttributes())
+		if service != "" {
+			log = log.With("service.name", service)
 		}
 
 		for _, scope := range r.ScopeSpans {
@@ -95,36 +90,76 @@ func (s *TraceServer) Export(ctx context.Context, req *pbCollectorTrace.ExportTr
 			}
 
 			for _, span := range scope.Spans {
-				found := false
 				name := span.GetName()
 				log := log.With(slog.String("name", name))
-				for _, match := range s.matches {
+				item := Item{
+					Signal:       "trace",
+					Service:      service,
+					Scope:        scope.GetScope().GetName(),
+					ScopeVersion: scope.GetScope().GetVersion(),
+					Name:         name,
+					Kind:         spanKind(span.GetKind()),
+					Attributes:   span.GetAttributes(),
+					Resource:     r.GetResource().GetAttributes(),
+					Profile:      profile,
+					Session:      session,
+				}
+				highName := false
+				if cfg.cardinality.limited {
+					// Both are checked, so the name is counted.
+					tooMany := s.cardinality.observeSpanName(cfg.cardinality, service, item.Scope, name)
+					highName = tooMany || hasIDInName(name)
+				}
+				for _, match := range matches {
 					if !match.isMatch(name, span.GetAttributes()) {
 						continue
 					}
 
-					missing := match.compareAttributes(log, span.GetAttributes(), scope.GetScope().GetAttributes(), r.GetResource().GetAttributes())
-					found = true
-					count += missing
-					if missing > 0 {
-						names = append(names, fmt.Sprintf("%s/%s", scope.Scope.GetName(), span.Name))
+					findings := match.compareAttributes(span.GetAttributes(), scope.GetScope().GetAttributes(), r.GetResource().GetAttributes())
+					nameFindings, want := match.spanNameFindings(name, span.GetAttributes(), highName)
+					findings = append(findings, nameFindings...)
+					// Observers see the baselined findings, so they count
+					// against compliance.
+					kept := cfg.baseline.filter(item, findings)
+					logFindings(log, kept)
+					if want != "" && slices.ContainsFunc(kept, func(f Finding) bool { return f.Category == SpanNameMismatch }) {
+						log.Info("span name doesn't follow the convention", slog.String("want", want))
 					}
+					item.Matched = true
+					item.Expected = append(item.Expected, match.expected...)
+					item.Groups = append(item.Groups, match.groups...)
+					item.Findings = append(item.Findings, findings...)
+					item.Failed = item.Failed || len(match.policy.failures(kept)) > 0
+					res.add(span, match.policy, kept)
 				}
-				if !found && s.reportUnmatched {
+				if !item.Matched && cfg.reportUnmatched {
 					log.Info("unmatched span")
 				}
+				if !item.Matched && highName {
+					log.Info("high cardinality span name")
+				}
+				s.observers.observe(item)
 			}
 		}
 	}
 
-	if count > 0 && !s.disableError {
+	if rejected, msg, ok := res.partialSuccess(); ok {
 		return &pbCollectorTrace.ExportTraceServiceResponse{
 			PartialSuccess: &pbCollectorTrace.ExportTracePartialSuccess{
-				RejectedSpans: int64(count),
-				ErrorMessage:  "missing attributes",
+				RejectedSpans: rejected,
+				ErrorMessage:  msg,
 			},
-		}, status.Error(codes.FailedPrecondition, fmt.Sprintf("missing attributes: %v", names))
+		}, res.err()
 	}
 
 	return &pbCollectorTrace.ExportTraceServiceResponse{}, nil
 }
+
+// spanKind is the kind as in the semantic conventions, e.g. server, empty
+// when it is unspecified.
+func spanKind(kind pbTrace.Span_SpanKind) string {
+	if kind == pbTrace.Span_SPAN_KIND_UNSPECIFIED {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(kind.String(), "SPAN_KIND_"))
+}
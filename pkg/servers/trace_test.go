@@ -7,17 +7,23 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/attribute"
 	pbCollectorTrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
 	common "go.opentelemetry.io/proto/otlp/common/v1"
 	resource "go.opentelemetry.io/proto/otlp/resource/v1"
 	trace "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func TestTraceServerExport(t *testing.T) {
-	defaultServer := &TraceServer{
+	defaultServer := newTestTraceServer(&signalConfig{
 		matches: []matchDef{newTestMatchDef([]string{"test"}, nil)},
-	}
+	})
 	testCases := []struct {
 		name       string
 		traceAttrs []attribute.KeyValue
@@ -52,14 +58,22 @@ func TestTraceServerExport(t *testing.T) {
 			},
 		},
 		{
-			name: "Disable Error",
+			name: "Accept",
 			traceAttrs: []attribute.KeyValue{
 				attribute.String("notTest", "test"),
 			},
-			server: &TraceServer{
-				matches:      []matchDef{newTestMatchDef([]string{"test"}, nil)},
-				disableError: true,
+			server: newTestTraceServer(&signalConfig{
+				matches: []matchDef{newTestActionMatchDef([]string{"test"}, Accept)},
+			}),
+		},
+		{
+			name: "Partial Success",
+			traceAttrs: []attribute.KeyValue{
+				attribute.String("notTest", "test"),
 			},
+			server: newTestTraceServer(&signalConfig{
+				matches: []matchDef{newTestActionMatchDef([]string{"test"}, PartialSuccess)},
+			}),
 		},
 		{
 			name: "Match Scope Attrs",
@@ -88,8 +102,8 @@ func TestTraceServerExport(t *testing.T) {
 					t.Fatal("expected error, got nil")
 				}
 				errMsg := err.Error()
-				if !strings.Contains(errMsg, "TestScope") {
-					t.Errorf("expected error to contain %q, got %q", "TestScope", errMsg)
+				if !strings.Contains(errMsg, "test.group: test (1)") {
+					t.Errorf("expected error to contain %q, got %q", "test.group: test (1)", errMsg)
 				}
 			} else {
 				if err != nil {
@@ -151,3 +165,94 @@ func createKeyValues(attrs []attribute.KeyValue) []*common.KeyValue {
 func createValue(value string) *common.AnyValue {
 	return &common.AnyValue{Value: &common.AnyValue_StringValue{StringValue: value}}
 }
+
+func newTestTraceServer(cfg *signalConfig) *TraceServer {
+	s := &TraceServer{}
+	s.config.Store(cfg)
+	return s
+}
+
+func TestTraceServerUpdate(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	s, err := NewTraceService(Config{Trace: []Match{{Match: "first"}}}, svs)
+	require.NoError(t, err)
+	first := s.config.Load()
+
+	err = s.Update(Config{Trace: []Match{{Match: "second", Groups: []string{"unknown"}}}}, svs)
+	assert.Error(t, err)
+	assert.Same(t, first, s.config.Load(), "invalid config should keep the previous config")
+
+	err = s.Update(Config{Trace: []Match{{Match: "second"}}}, svs)
+	require.NoError(t, err)
+	assert.Equal(t, "second", s.config.Load().matches[0].name.String())
+}
+
+func TestTraceServerUpdateKeepsIgnoreHits(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	cfg := Config{Ignore: []string{"app.id"}, Trace: []Match{{Ignore: []string{"app.n*", "unused"}}}}
+	s, err := NewTraceService(cfg, svs)
+	require.NoError(t, err)
+	_, err = s.Export(context.Background(), newRequest([]attribute.KeyValue{attribute.String("app.id", "a"), attribute.String("app.name", "b")}, nil, nil))
+	require.NoError(t, err)
+
+	require.NoError(t, s.Update(cfg, svs))
+	assert.Equal(t, map[string][]string{"trace[0]": {"unused"}}, UnusedIgnores(s.IgnoreUsage()))
+	assert.Contains(t, s.IgnoreUsage(), IgnoreUsage{Section: "global", Pattern: "app.id", Hits: 1})
+}
+
+func TestTraceServerRejectedSpans(t *testing.T) {
+	s := newTestTraceServer(&signalConfig{
+		matches: []matchDef{newTestActionMatchDef([]string{"a", "b", "c"}, PartialSuccess)},
+	})
+	req := newRequest([]attribute.KeyValue{attribute.String("notTest", "test")}, nil, nil)
+	req.ResourceSpans[0].ScopeSpans[0].Spans = append(req.ResourceSpans[0].ScopeSpans[0].Spans, &trace.Span{Name: "second"})
+
+	resp, err := s.Export(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.GetPartialSuccess().GetRejectedSpans())
+	assert.Equal(t, "2 spans rejected: test.group: a (2), b (2), c (2)", resp.GetPartialSuccess().GetErrorMessage())
+}
+
+type itemRecorder struct{ items []Item }
+
+func (r *itemRecorder) Observe(item Item) { r.items = append(r.items, item) }
+
+func TestTraceServerProfiles(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	rec := &itemRecorder{}
+	s, err := NewTraceService(Config{
+		Trace: []Match{{Include: []string{"test"}}},
+		Profiles: map[string]Profile{
+			"lenient": {ErrorPolicy: ErrorPolicies{ErrorPolicy: ErrorPolicy{Action: string(Accept)}}},
+		},
+	}, svs, rec)
+	require.NoError(t, err)
+	req := newRequest([]attribute.KeyValue{attribute.String("notTest", "test")}, nil, nil)
+
+	_, err = s.Export(context.Background(), req)
+	assert.Error(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ProfileHeader, "lenient", SessionHeader, "ci-1"))
+	_, err = s.Export(ctx, req)
+	assert.NoError(t, err)
+	require.Len(t, rec.items, 2)
+	assert.Equal(t, "lenient", rec.items[1].Profile)
+	assert.Equal(t, "ci-1", rec.items[1].Session)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(ProfileHeader, "lenent"))
+	_, err = s.Export(ctx, req)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.ErrorContains(t, err, `unknown profile "lenent", did you mean "lenient"?`)
+}
+
+func TestSpanKind(t *testing.T) {
+	assert.Equal(t, "server", spanKind(trace.Span_SPAN_KIND_SERVER))
+	assert.Equal(t, "consumer", spanKind(trace.Span_SPAN_KIND_CONSUMER))
+	assert.Empty(t, spanKind(trace.Span_SPAN_KIND_UNSPECIFIED))
+}
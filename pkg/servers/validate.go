@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+)
+
+// Validate checks the config against the semantic versions. Every problem
+// found is reported, joined into one error.
+func (c Config) Validate(svs map[string]semconv.SemanticVersion) error {
+	global, errs := sharedPatterns("global", "ignore", c.Ignore)
+	if c.ServerAddress == "" {
+		errs = append(errs, errors.New("server_address: empty"))
+	}
+	if c.SelfTelemetry.Interval < 0 {
+		errs = append(errs, fmt.Errorf("self_telemetry.interval: negative %s", c.SelfTelemetry.Interval))
+	}
+	if c.Baseline != "" {
+		if _, err := ReadBaseline(c.Baseline); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, c.validatePolicies("trace", "metrics", "log")...)
+	_, cerrs := newCardinalityConfig(c.Cardinality)
+	errs = append(errs, cerrs...)
+	if _, err := newMatchDef("resource", c.Resource, global, defaultPolicy, svs); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := newMatchDefs("trace", c.Trace, global, defaultPolicy, svs); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := newMatchDefs("metrics", c.Metrics, global, defaultPolicy, svs); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := newMatchDefs("log", c.Log, global, defaultPolicy, svs); err != nil {
+		errs = append(errs, err)
+	}
+	for i, svc := range c.Services {
+		section := fmt.Sprintf("services[%d]", i)
+		_, ignore, serrs := newServiceSelector(section, svc, svs)
+		errs = append(errs, serrs...)
+		if _, ok := svs[svc.SemanticVersion]; svc.SemanticVersion != "" && !ok {
+			continue
+		}
+		shared := append(slices.Clone(global), ignore...)
+		for _, signal := range []string{"trace", "metrics", "log"} {
+			// The global matches are only different with another version.
+			if svc.SemanticVersion != "" {
+				if _, err := newMatchDefs(signal, svc.withVersion(c.forSignal(signal)), shared, defaultPolicy, svs); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", section, err))
+				}
+			}
+			if _, err := newMatchDefs(section+"."+signal, svc.withVersion(svc.forSignal(signal)), shared, defaultPolicy, svs); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, name := range profileNames(c.Profiles) {
+		errs = append(errs, c.validateProfile(name, global, svs)...)
+	}
+	errs = append(errs, c.validateExpectations(svs)...)
+	return errors.Join(errs...)
+}
+
+// validateExpectations checks the services are named, and the groups are in
+// any of the semantic versions and in a match of their signal, as only those
+// can be met.
+func (c Config) validateExpectations(svs map[string]semconv.SemanticVersion) []error {
+	errs := []error{}
+	if c.ExpectationTimeout < 0 {
+		errs = append(errs, fmt.Errorf("expectation_timeout: negative %s", c.ExpectationTimeout))
+	}
+	groups := map[string]bool{}
+	for _, sv := range svs {
+		for id := range sv.Groups {
+			groups[id] = true
+		}
+	}
+	for i, e := range c.Expectations {
+		section := fmt.Sprintf("expectations[%d]", i)
+		if e.Service == "" {
+			errs = append(errs, fmt.Errorf("%s: service: empty", section))
+		}
+		if len(e.Trace)+len(e.Metrics)+len(e.Log) == 0 {
+			errs = append(errs, fmt.Errorf("%s: trace, metrics or log is required", section))
+		}
+		for _, list := range []struct {
+			name   string
+			groups []string
+		}{{"trace", e.Trace}, {"log", e.Log}} {
+			matched := c.matchedGroups(list.name)
+			for _, g := range list.groups {
+				switch {
+				case !groups[g]:
+					errs = append(errs, fmt.Errorf("%s: %s: %w", section, list.name, unknownError("group", g, mapKeys(groups))))
+				case !matched[g]:
+					errs = append(errs, fmt.Errorf("%s: %s: group %s isn't in any %s match, so it can't be met", section, list.name, g, list.name))
+				}
+			}
+		}
+		for _, m := range e.Metrics {
+			if m == "" {
+				errs = append(errs, fmt.Errorf("%s: metrics: empty entry", section))
+			}
+		}
+	}
+	return errs
+}
+
+// matchedGroups are the groups of the matches of the signal, including those
+// of the services and profiles.
+func (c Config) matchedGroups(signal string) map[string]bool {
+	matches := slices.Clone(c.forSignal(signal))
+	for _, svc := range c.Services {
+		matches = append(matches, svc.forSignal(signal)...)
+	}
+	for _, p := range c.Profiles {
+		matches = append(matches, p.forSignal(signal)...)
+	}
+	groups := map[string]bool{}
+	for _, m := range matches {
+		for _, g := range m.Groups {
+			groups[g] = true
+		}
+	}
+	return groups
+}
+
+// validateProfile checks what the profile changes, the rest of the config is
+// checked on its own.
+func (c Config) validateProfile(name string, global []pattern, svs map[string]semconv.SemanticVersion) []error {
+	p := c.Profiles[name]
+	section := "profiles." + name
+	ignore, errs := sharedPatterns(section, section+": ignore", p.Ignore)
+	errs = append(errs, p.ErrorPolicy.validate(section+".error_policy", "trace", "metrics", "log")...)
+	if _, ok := svs[p.SemanticVersion]; p.SemanticVersion != "" && !ok {
+		return append(errs, fmt.Errorf("%s: %w", section, unknownError("semantic_version", p.SemanticVersion, mapKeys(svs))))
+	}
+	shared := append(slices.Clone(global), ignore...)
+	for _, signal := range []string{"trace", "metrics", "log"} {
+		if matches := p.forSignal(signal); matches != nil {
+			if _, err := newMatchDefs(section+"."+signal, withVersion(p.SemanticVersion, matches), shared, defaultPolicy, svs); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		// The global matches are only different with another version.
+		if p.SemanticVersion != "" {
+			if _, err := newMatchDefs(signal, withVersion(p.SemanticVersion, c.forSignal(signal)), shared, defaultPolicy, svs); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", section, err))
+			}
+		}
+	}
+	return errs
+}
+
+func unknownError(kind, name string, candidates []string) error {
+	if s := suggest(name, candidates); s != "" {
+		return fmt.Errorf("unknown %s %q, did you mean %q?", kind, name, s)
+	}
+	return fmt.Errorf("unknown %s %q", kind, name)
+}
+
+// suggest finds the candidate closest to name. A candidate that ends with
+// name, like trace.http.server for http.server, is preferred, otherwise the
+// closest by edit distance is used if it is close enough to be a typo.
+func suggest(name string, candidates []string) string {
+	sort.Strings(candidates)
+	for _, c := range candidates {
+		if strings.HasSuffix(c, "."+name) {
+			return c
+		}
+	}
+
+	best, bestDist := "", len(name)/3+2
+	for _, c := range candidates {
+		if d := levenshtein(name, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func mapKeys[K ~string, V any](m map[K]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, string(k))
+	}
+	return keys
+}
@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package servers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/semconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr []string
+	}{
+		{
+			name: "valid",
+			cfg: Config{
+				ServerAddress: "localhost:4317",
+				Trace:         []Match{{Match: "http.server.*", Groups: []string{"trace.http.server"}, Ignore: []string{"net.*"}}},
+			},
+		},
+		{
+			name: "unknown group",
+			cfg: Config{
+				ServerAddress: "localhost:4317",
+				Trace:         []Match{{Groups: []string{"trace.http.sever"}}},
+			},
+			wantErr: []string{`trace[0]: unknown group "trace.http.sever", did you mean "trace.http.server"?`},
+		},
+		{
+			name: "unknown semantic version",
+			cfg: Config{
+				ServerAddress: "localhost:4317",
+				Log:           []Match{{SemanticVersion: "https://opentelemetry.io/schemas/1.25.0", Groups: []string{"host"}}},
+			},
+			wantErr: []string{`log[0]: unknown semantic_version "https://opentelemetry.io/schemas/1.25.0"`},
+		},
+		{
+			name: "invalid regex",
+			cfg: Config{
+				ServerAddress: "localhost:4317",
+				Ignore:        []string{"/[/"},
+				Trace:         []Match{{Match: "http.(server"}},
+			},
+			wantErr: []string{
+				`ignore: invalid pattern "/[/"`,
+				`trace[0]: invalid match "http.(server"`,
+			},
+		},
+		{
+			name: "empty entries",
+			cfg: Config{
+				ServerAddress: "localhost:4317",
+				Resource:      Match{Groups: []string{""}, Ignore: []string{""}},
+				Trace:         []Match{{Include: []string{""}, MatchAttributes: []Attribute{{}}}},
+			},
+			wantErr: []string{
+				"resource: groups: empty entry",
+				"resource: ignore: empty entry",
+				"trace[0]: include: empty entry",
+				"trace[0]: match_attributes: empty name",
+			},
+		},
+		{
+			name: "invalid assertions",
+			cfg: Config{
+				ServerAddress: "localhost:4317",
+				Trace: []Match{{Assertions: []Assertion{
+					{Pattern: "^https?$"},
+					{Name: "url.scheme", Pattern: "(http"},
+					{Name: "server.port", Min: ptr(65535.0), Max: ptr(1.0)},
+					{Name: "http.route"},
+				}}},
+			},
+			wantErr: []string{
+				"trace[0]: assertions[0]: empty name",
+				`trace[0]: assertions[1]: invalid pattern "(http"`,
+				"trace[0]: assertions[2]: min 65535 is greater than max 1",
+				"trace[0]: assertions[3]: pattern, values, min or max is required",
+			},
+		},
+		{
+			name: "invalid experimental",
+			cfg: Config{
+				ServerAddress: "localhost:4317",
+				Trace:         []Match{{Experimental: "warning"}},
+			},
+			wantErr: []string{
+				`trace[0]: unknown experimental "warning", did you mean "warn"?`,
+			},
+		},
+		{
+			name: "experimental without stability",
+			cfg: Config{
+				ServerAddress: "localhost:4317",
+				Trace:         []Match{{Experimental: "exclude"}},
+			},
+			wantErr: []string{
+				"trace[0]: experimental: exclude needs a semantic_version that marks attributes stable, https://opentelemetry.io/schemas/1.21.0 doesn't",
+			},
+		},
+		{
+			name: "invalid cardinality",
+			cfg: Config{
+				ServerAddress: "localhost:4317",
+				Cardinality:   Cardinality{Limit: -1, SpanNameLimit: -2, Window: -time.Minute, Unbounded: []string{""}},
+				Trace:         []Match{{SpanName: []string{"{http.request.method} {http.route", "{}"}}},
+			},
+			wantErr: []string{
+				"cardinality.limit: negative -1",
+				"cardinality.span_name_limit: negative -2",
+				`trace[0]: span_name: unclosed placeholder in "{http.request.method} {http.route"`,
+				`trace[0]: span_name: empty placeholder in "{}"`,
+				"cardinality.window: negative -1m0s",
+				"cardinality.unbounded: empty entry",
+			},
+		},
+		{
+			name: "negative self telemetry interval",
+			cfg: Config{
+				ServerAddress: "localhost:4317",
+				SelfTelemetry: SelfTelemetry{Endpoint: "localhost:4317", Interval: -time.Second},
+			},
+			wantErr: []string{"self_telemetry.interval: negative -1s"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate(svs)
+			if len(tt.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, want := range tt.wantErr {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"trace.http.server", "trace.http.client", "host", "os"}
+
+	assert.Equal(t, "trace.http.client", suggest("trace.http.clinet", candidates))
+	assert.Equal(t, "trace.http.server", suggest("http.server", candidates))
+	assert.Equal(t, "", suggest("database", candidates))
+}
+
+func TestConfigValidateProfiles(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	cfg := Config{
+		ServerAddress: "localhost:4317",
+		Trace:         []Match{{Groups: []string{"trace.http.server"}}},
+		Profiles: map[string]Profile{
+			"ci":     {Ignore: []string{""}, ErrorPolicy: ErrorPolicies{Trace: ErrorPolicy{Action: "rejct"}}},
+			"old":    {SemanticVersion: "1.0.0"},
+			"strict": {Log: []Match{{Groups: []string{"nope"}}}},
+		},
+	}
+	err = cfg.Validate(svs)
+	require.Error(t, err)
+	for _, want := range []string{
+		"profiles.ci: ignore: empty entry",
+		`profiles.ci.error_policy.trace: unknown action "rejct"`,
+		`profiles.old: unknown semantic_version "1.0.0"`,
+		`profiles.strict.log[0]: unknown group "nope"`,
+	} {
+		assert.ErrorContains(t, err, want)
+	}
+}
+
+func TestConfigValidateExpectations(t *testing.T) {
+	svs, err := semconv.ParseSemanticVersion()
+	require.NoError(t, err)
+
+	cfg := Config{
+		ServerAddress:      "localhost:4317",
+		ExpectationTimeout: -time.Minute,
+		Profiles:           map[string]Profile{"ci": {Trace: []Match{{Groups: []string{"trace.http.server"}}}}},
+		Expectations: []Expectation{
+			{Service: "api", Trace: []string{"trace.http.server"}, Metrics: []string{"http.server.duration"}},
+			{Trace: []string{"trace.http.sever"}, Metrics: []string{""}},
+			{Service: "web"},
+			{Service: "api", Trace: []string{"trace.http.client"}, Log: []string{"trace.http.server"}},
+		},
+	}
+	err = cfg.Validate(svs)
+	require.Error(t, err)
+	assert.Len(t, flatten(err), 7)
+	for _, want := range []string{
+		"expectation_timeout: negative -1m0s",
+		"expectations[1]: service: empty",
+		`expectations[1]: trace: unknown group "trace.http.sever", did you mean "trace.http.server"?`,
+		"expectations[1]: metrics: empty entry",
+		"expectations[2]: trace, metrics or log is required",
+		"expectations[3]: trace: group trace.http.client isn't in any trace match, so it can't be met",
+		"expectations[3]: log: group trace.http.server isn't in any log match, so it can't be met",
+	} {
+		assert.ErrorContains(t, err, want)
+	}
+}
+
+func flatten(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	return joined.Unwrap()
+}
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+)
+
+// Client calls the session control API of a checker, for tests that check
+// the telemetry of a workload:
+//
+//	c := session.NewClient("http://localhost:9464", nil)
+//	_ = c.Start(ctx, "TestHTTP")
+//	// export with the session.Headers("TestHTTP", "") headers, then
+//	v, _ := c.End(ctx, "TestHTTP")
+type Client struct {
+	base string
+	http *http.Client
+}
+
+// NewClient creates a client for the checker at the http_address base URL.
+// A nil httpClient uses http.DefaultClient.
+func NewClient(base string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{base: strings.TrimSuffix(base, "/"), http: httpClient}
+}
+
+// Headers are the OTLP exporter headers that send telemetry to the session,
+// and select the profile if it isn't empty.
+func Headers(session, profile string) map[string]string {
+	h := map[string]string{servers.SessionHeader: session}
+	if profile != "" {
+		h[servers.ProfileHeader] = profile
+	}
+	return h
+}
+
+// Start starts the session.
+func (c *Client) Start(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPost, name, nil)
+}
+
+// Status returns the verdict of the session so far.
+func (c *Client) Status(ctx context.Context, name string) (Verdict, error) {
+	v := Verdict{}
+	err := c.do(ctx, http.MethodGet, name, &v)
+	return v, err
+}
+
+// End ends the session and returns its verdict.
+func (c *Client) End(ctx context.Context, name string) (Verdict, error) {
+	v := Verdict{}
+	err := c.do(ctx, http.MethodDelete, name, &v)
+	return v, err
+}
+
+func (c *Client) do(ctx context.Context, method, name string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.base+Prefix+url.PathEscape(name), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		e := errorResponse{}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		var err error
+		switch resp.StatusCode {
+		case http.StatusConflict:
+			err = ErrExists
+		case http.StatusNotFound:
+			err = ErrNotFound
+		default:
+			err = errors.New(e.Error)
+		}
+		return fmt.Errorf("session %q: %s: %w", name, resp.Status, err)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
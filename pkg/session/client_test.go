@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/expect"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientLifecycle(t *testing.T) {
+	store := NewStore()
+	mux := http.NewServeMux()
+	mux.Handle(Prefix, Handler(store))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := NewClient(srv.URL+"/", nil)
+
+	require.NoError(t, c.Start(ctx, "Test HTTP/1"))
+	assert.ErrorIs(t, c.Start(ctx, "Test HTTP/1"), ErrExists)
+
+	v, err := c.Status(ctx, "Test HTTP/1")
+	require.NoError(t, err)
+	assert.True(t, v.Passed)
+	assert.Equal(t, 0, v.Items)
+
+	store.Observe(servers.Item{Signal: "trace", Service: "api", Name: "GET /", Session: "Test HTTP/1", Matched: true, Failed: true, Findings: []servers.Finding{
+		{Group: "trace.http.server", Attribute: "url.scheme", Category: servers.RequiredMissing},
+	}})
+	store.Observe(servers.Item{Signal: "trace", Service: "api", Name: "GET /", Session: "other", Matched: true})
+
+	v, err = c.End(ctx, "Test HTTP/1")
+	require.NoError(t, err)
+	assert.Equal(t, Verdict{
+		Session: "Test HTTP/1",
+		Passed:  false,
+		Items:   1,
+		Failed:  1,
+		Findings: []servers.BaselineEntry{
+			{Signal: "trace", Service: "api", Name: "GET /", Group: "trace.http.server", Attribute: "url.scheme", Category: servers.RequiredMissing},
+		},
+		Unmet:  []expect.Unmet{},
+		Report: v.Report,
+	}, v)
+	require.Len(t, v.Report.Scopes, 1)
+
+	_, err = c.End(ctx, "Test HTTP/1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestHandlerMethods(t *testing.T) {
+	h := Handler(NewStore())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, Prefix+"a", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Prefix, nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHeaders(t *testing.T) {
+	assert.Equal(t, map[string]string{servers.SessionHeader: "s"}, Headers("s", ""))
+	assert.Equal(t, map[string]string{servers.SessionHeader: "s", servers.ProfileHeader: "ci"}, Headers("s", "ci"))
+}
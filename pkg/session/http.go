@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Prefix is the path the Handler is served on.
+const Prefix = "/sessions/"
+
+// Handler serves the session control API:
+//
+//	POST   /sessions/{name}  starts the session.
+//	GET    /sessions/{name}  returns the verdict so far.
+//	DELETE /sessions/{name}  ends the session and returns its verdict.
+func Handler(s *Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		escaped := strings.TrimPrefix(r.URL.EscapedPath(), Prefix)
+		name, err := url.PathUnescape(escaped)
+		if err != nil || name == "" || strings.Contains(escaped, "/") {
+			writeError(w, http.StatusNotFound, errors.New("expected "+Prefix+"{name}"))
+			return
+		}
+
+		var v Verdict
+		switch r.Method {
+		case http.MethodPost:
+			if err := s.Start(name); err != nil {
+				writeError(w, statusCode(err), err)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			return
+		case http.MethodGet:
+			v, err = s.Status(name)
+		case http.MethodDelete:
+			v, err = s.End(name)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		if err != nil {
+			writeError(w, statusCode(err), err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	})
+}
+
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrExists):
+		return http.StatusConflict
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package session keeps the results of each session apart, so concurrent test
+// runs sharing a checker only see their own findings.
+package session
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/expect"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/score"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+)
+
+// idleTimeout is how long a named session is kept after it was last sent to,
+// so the sessions of CI jobs that never end them don't pile up.
+const idleTimeout = time.Hour
+
+// Store is a servers.Observer that collects the items of each session, named
+// by the x-semconv-session header. Items without the header are in the
+// unnamed session "".
+type Store struct {
+	// all is the scorecard of every item, whatever its session.
+	all *score.Scorecard
+	now func() time.Time
+
+	mu           sync.Mutex
+	sessions     map[string]*session
+	expectations []servers.Expectation
+}
+
+type session struct {
+	scorecard *score.Scorecard
+	findings  *servers.BaselineRecorder
+	seen      *expect.Seen
+
+	mu     sync.Mutex
+	items  int
+	failed int
+	// last is when the session was started or last sent to.
+	last time.Time
+	// started is set when the session was started, rather than created by
+	// an item sent with its header.
+	started bool
+}
+
+var (
+	// ErrExists is returned when starting a session that already exists.
+	ErrExists = errors.New("session already exists")
+	// ErrNotFound is returned for a session that was never started or sent to.
+	ErrNotFound = errors.New("session not found")
+	errNoName   = errors.New("session name is required")
+)
+
+// Verdict is the outcome of a session.
+type Verdict struct {
+	Session string `json:"session"`
+	// Passed is set if no item failed the fail_on categories of its match,
+	// and every expectation was met.
+	Passed bool `json:"passed"`
+	Items  int  `json:"items"`
+	Failed int  `json:"failed"`
+	// Findings are the distinct findings of the session.
+	Findings []servers.BaselineEntry `json:"findings"`
+	// Unmet is the expected telemetry that wasn't sent in the session.
+	Unmet  []expect.Unmet `json:"unmet"`
+	Report score.Report   `json:"report"`
+}
+
+var _ servers.Observer = &Store{}
+
+func NewStore() *Store {
+	return &Store{all: score.New(), now: time.Now, sessions: map[string]*session{}}
+}
+
+func (s *Store) Observe(item servers.Item) {
+	s.all.Observe(item)
+	sess := s.get(item.Session, true)
+	sess.scorecard.Observe(item)
+	sess.findings.Observe(item)
+	sess.seen.Observe(item)
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.items++
+	if item.Failed {
+		sess.failed++
+	}
+	sess.last = s.now()
+}
+
+// get returns the session, creating it if create is set.
+func (s *Store) get(name string, create bool) *session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[name]
+	if !ok && create {
+		s.expire()
+		sess = newSession(s.now())
+		s.sessions[name] = sess
+	}
+	return sess
+}
+
+// expire removes the named sessions that were idle longer than idleTimeout.
+// It must be called with mu held.
+func (s *Store) expire() {
+	now := s.now()
+	for name, sess := range s.sessions {
+		if name == "" {
+			continue
+		}
+		sess.mu.Lock()
+		idle := now.Sub(sess.last) > idleTimeout
+		sess.mu.Unlock()
+		if idle {
+			delete(s.sessions, name)
+		}
+	}
+}
+
+func newSession(now time.Time) *session {
+	return &session{scorecard: score.New(), findings: servers.NewBaselineRecorder(), seen: expect.NewSeen(), last: now}
+}
+
+// SetExpectations sets the expectations the verdicts are checked against.
+func (s *Store) SetExpectations(expectations []servers.Expectation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expectations = expectations
+}
+
+// Start creates an empty session. Items sent with the session header before
+// it is started are kept, but a session can't be started twice.
+func (s *Store) Start(name string) error {
+	if name == "" {
+		return errNoName
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sess, ok := s.sessions[name]; ok {
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		if sess.started {
+			return ErrExists
+		}
+		sess.started = true
+		sess.last = s.now()
+		return nil
+	}
+	s.expire()
+	sess := newSession(s.now())
+	sess.started = true
+	s.sessions[name] = sess
+	return nil
+}
+
+// Status is the verdict of the session so far.
+func (s *Store) Status(name string) (Verdict, error) {
+	sess := s.get(name, false)
+	if sess == nil {
+		return Verdict{}, ErrNotFound
+	}
+	return sess.verdict(name, s.Expectations()), nil
+}
+
+// End removes the session and returns its verdict. Items sent with the
+// session header afterwards start a new session.
+func (s *Store) End(name string) (Verdict, error) {
+	s.mu.Lock()
+	sess, ok := s.sessions[name]
+	delete(s.sessions, name)
+	expectations := s.expectations
+	s.mu.Unlock()
+	if !ok {
+		return Verdict{}, ErrNotFound
+	}
+	return sess.verdict(name, expectations), nil
+}
+
+// Expectations are the expectations the verdicts are checked against.
+func (s *Store) Expectations() []servers.Expectation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.expectations
+}
+
+func (sess *session) verdict(name string, expectations []servers.Expectation) Verdict {
+	sess.mu.Lock()
+	items, failed := sess.items, sess.failed
+	sess.mu.Unlock()
+	unmet := sess.seen.Unmet(expectations)
+	return Verdict{
+		Session:  name,
+		Passed:   failed == 0 && len(unmet) == 0,
+		Items:    items,
+		Failed:   failed,
+		Findings: sess.findings.Baseline().Findings,
+		Unmet:    unmet,
+		Report:   sess.scorecard.Report(),
+	}
+}
+
+// Report is the compliance report of the session. ok is false if the session
+// doesn't exist.
+func (s *Store) Report(name string) (r score.Report, ok bool) {
+	sess := s.get(name, false)
+	if sess == nil {
+		return score.Report{Scopes: []score.ScopeScore{}}, false
+	}
+	return sess.scorecard.Report(), true
+}
+
+// ReportAll is the compliance report of every item, whatever its session,
+// including the sessions that ended or expired.
+func (s *Store) ReportAll() score.Report {
+	return s.all.Report()
+}
+
+// Findings are the distinct findings of the session.
+func (s *Store) Findings(name string) []servers.BaselineEntry {
+	sess := s.get(name, false)
+	if sess == nil {
+		return []servers.BaselineEntry{}
+	}
+	return sess.findings.Baseline().Findings
+}
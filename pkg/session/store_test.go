@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/expect"
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreIsolatesSessions(t *testing.T) {
+	s := NewStore()
+	s.Observe(servers.Item{Signal: "trace", Service: "api", Name: "GET /", Session: "ci-1", Matched: true, Findings: []servers.Finding{
+		{Group: "trace.http.server", Attribute: "url.scheme", Category: servers.RequiredMissing},
+	}})
+	s.Observe(servers.Item{Signal: "trace", Service: "web", Name: "GET /", Session: "ci-2", Matched: true})
+	s.Observe(servers.Item{Signal: "trace", Service: "other", Name: "GET /", Matched: true})
+
+	r, ok := s.Report("ci-1")
+	require.True(t, ok)
+	require.Len(t, r.Scopes, 1)
+	assert.Equal(t, "api", r.Scopes[0].Service)
+	assert.Len(t, s.Findings("ci-1"), 1)
+
+	r, ok = s.Report("ci-2")
+	require.True(t, ok)
+	require.Len(t, r.Scopes, 1)
+	assert.Equal(t, "web", r.Scopes[0].Service)
+	assert.Empty(t, s.Findings("ci-2"))
+
+	r, ok = s.Report("")
+	require.True(t, ok)
+	assert.Equal(t, "other", r.Scopes[0].Service)
+
+	_, ok = s.Report("unknown")
+	assert.False(t, ok)
+
+	_, err := s.End("ci-1")
+	require.NoError(t, err)
+	assert.Len(t, s.ReportAll().Scopes, 3, "the report of all items keeps ended sessions")
+}
+
+func TestStoreExpiresIdleSessions(t *testing.T) {
+	s := NewStore()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	s.Observe(servers.Item{Signal: "trace", Session: "ci-1"})
+	s.Observe(servers.Item{Signal: "trace"})
+	require.NoError(t, s.Start("ci-2"))
+
+	now = now.Add(idleTimeout / 2)
+	s.Observe(servers.Item{Signal: "trace", Session: "ci-2"})
+	now = now.Add(idleTimeout/2 + time.Second)
+	s.Observe(servers.Item{Signal: "trace", Session: "ci-3"})
+
+	_, err := s.Status("ci-1")
+	assert.ErrorIs(t, err, ErrNotFound, "idle sessions expire")
+	_, err = s.Status("ci-2")
+	assert.NoError(t, err)
+	_, ok := s.Report("")
+	assert.True(t, ok, "the unnamed session doesn't expire")
+}
+
+func TestStoreVerdictExpectations(t *testing.T) {
+	s := NewStore()
+	s.SetExpectations([]servers.Expectation{{Service: "api", Trace: []string{"trace.http.server"}, Metrics: []string{"http.server.duration"}}})
+	require.NoError(t, s.Start("ci"))
+	s.Observe(servers.Item{Signal: "trace", Service: "api", Session: "ci", Matched: true, Groups: []string{"trace.http.server"}})
+
+	v, err := s.Status("ci")
+	require.NoError(t, err)
+	assert.False(t, v.Passed)
+	assert.Equal(t, []expect.Unmet{{Service: "api", Signal: "metrics", Name: "http.server.duration"}}, v.Unmet)
+
+	s.Observe(servers.Item{Signal: "metrics", Service: "api", Session: "ci", Name: "http.server.duration"})
+	v, err = s.End("ci")
+	require.NoError(t, err)
+	assert.True(t, v.Passed)
+}
+
+func TestStoreStartAfterItems(t *testing.T) {
+	s := NewStore()
+	s.Observe(servers.Item{Signal: "trace", Service: "api", Session: "ci", Matched: true})
+
+	require.NoError(t, s.Start("ci"), "a session created by its items can be started")
+	assert.ErrorIs(t, s.Start("ci"), ErrExists)
+
+	v, err := s.End("ci")
+	require.NoError(t, err)
+	assert.Equal(t, 1, v.Items, "items sent before the start are kept")
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package stats exposes compliance statistics of the checked telemetry as
+// Prometheus metrics.
+package stats
+
+import (
+	"sync"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "semconv_checker"
+
+// Stats counts the items checked by the servers. It is a servers.Observer.
+type Stats struct {
+	received   *prometheus.CounterVec
+	checked    *prometheus.CounterVec
+	unmatched  *prometheus.CounterVec
+	violations *prometheus.CounterVec
+	compliance *prometheus.GaugeVec
+
+	mu       sync.Mutex
+	services map[string]*serviceCount
+}
+
+type serviceCount struct {
+	checked   int
+	compliant int
+}
+
+var _ servers.Observer = &Stats{}
+
+// New creates the metrics and registers them with reg.
+func New(reg prometheus.Registerer) (*Stats, error) {
+	s := &Stats{
+		received: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "items_received_total",
+			Help:      "Spans, data points and log records received.",
+		}, []string{"signal", "service"}),
+		checked: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "items_checked_total",
+			Help:      "Items matched by at least one match and checked.",
+		}, []string{"signal", "service"}),
+		unmatched: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "items_unmatched_total",
+			Help:      "Items not matched by any match.",
+		}, []string{"signal", "service"}),
+		violations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "violations_total",
+			Help:      "Attributes that don't follow the semantic conventions, the attribute is empty unless it is defined by a group.",
+		}, []string{"signal", "group", "attribute", "category"}),
+		compliance: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "compliance_ratio",
+			Help:      "Ratio of checked items with no findings other than extra attributes.",
+		}, []string{"service"}),
+		services: map[string]*serviceCount{},
+	}
+
+	for _, c := range []prometheus.Collector{s.received, s.checked, s.unmatched, s.violations, s.compliance} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Stats) Observe(item servers.Item) {
+	s.received.WithLabelValues(item.Signal, item.Service).Inc()
+	if !item.Matched {
+		s.unmatched.WithLabelValues(item.Signal, item.Service).Inc()
+		return
+	}
+	s.checked.WithLabelValues(item.Signal, item.Service).Inc()
+	for _, f := range item.Findings {
+		// Extra attributes are named by the telemetry, so they aren't
+		// labelled to bound the series.
+		attribute := ""
+		if f.Defined() {
+			attribute = f.Attribute
+		}
+		s.violations.WithLabelValues(item.Signal, f.Group, attribute, string(f.Category)).Inc()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := s.services[item.Service]
+	if count == nil {
+		count = &serviceCount{}
+		s.services[item.Service] = count
+	}
+	count.checked++
+	if item.Compliant() {
+		count.compliant++
+	}
+	s.compliance.WithLabelValues(item.Service).Set(float64(count.compliant) / float64(count.checked))
+}
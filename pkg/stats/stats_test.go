@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package stats
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madvikinggod/otel-semconv-checker/pkg/servers"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsObserve(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	s, err := New(reg)
+	require.NoError(t, err)
+
+	s.Observe(servers.Item{Signal: "trace", Service: "api", Matched: true})
+	s.Observe(servers.Item{Signal: "trace", Service: "api", Matched: true, Findings: []servers.Finding{
+		{Group: "trace.http.server", Attribute: "url.scheme", Category: servers.RequiredMissing},
+	}})
+	s.Observe(servers.Item{Signal: "trace", Service: "api", Matched: true, Findings: []servers.Finding{
+		{Attribute: "custom", Category: servers.Extra},
+	}})
+	s.Observe(servers.Item{Signal: "log", Service: "api"})
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(s.received.WithLabelValues("trace", "api")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.received.WithLabelValues("log", "api")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(s.checked.WithLabelValues("trace", "api")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.unmatched.WithLabelValues("log", "api")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.violations.WithLabelValues("trace", "trace.http.server", "url.scheme", "required-missing")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.violations.WithLabelValues("trace", "", "", "extra")), "extra attributes aren't labelled")
+	assert.InDelta(t, 2.0/3.0, testutil.ToFloat64(s.compliance.WithLabelValues("api")), 0.0001)
+
+	rec := httptest.NewRecorder()
+	promhttp.HandlerFor(reg, promhttp.HandlerOpts{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.Contains(rec.Body.String(), `semconv_checker_items_received_total{service="api",signal="trace"} 3`), rec.Body.String())
+}
+
+func TestNewRegisterTwice(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	_, err := New(reg)
+	require.NoError(t, err)
+	_, err = New(reg)
+	assert.Error(t, err)
+}